@@ -0,0 +1,374 @@
+// Package encode turns Go structs, including generated protobuf messages, into
+// the signals a brain takes in, and its outputs back into a struct.
+//
+// Each field of the struct is one neuron. Numbers and bools are a single
+// signal, while strings, slices and arrays are a signal per element. Nested
+// structs are flattened into their fields, so they take a neuron for each of
+// their fields. Fields tagged with `evolve:"-"` are left out, as are
+// unexported ones.
+//
+// Numbers are scaled by the One of the signal width, so that a Fixed signal
+// holds the same number as the field, and then clamped between 0 and the max
+// signal. Bools are 0 or the max signal.
+//
+// A 0 signal is the neuron.NullRune that ends a brain's inputs and outputs,
+// and false, zero and negative numbers all encode to it. An input neuron stops
+// at its first 0, so the elements of a string or slice after one are never
+// seen, and an output can't give a 0 before the end of a string or slice
+// either. Offset fields whose zero matters, such as counting from 1 instead.
+//
+// Fields of a protobuf message are taken in the order of their field numbers,
+// rather than the order they're declared in, so that adding a field to the
+// message only adds a neuron. Oneof fields aren't supported.
+package encode
+
+import (
+	"fmt"
+	"hackathon/sam/evolve/neuron"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// TagName is the struct tag read by this package.
+const TagName = "evolve"
+
+// field is the path to a field from the top-level struct, through any nested
+// structs.
+type field struct {
+	index []int
+	name  string
+}
+
+// Encode returns the inputs for the SENSE neurons from a struct or a pointer to
+// one.
+func Encode[S neuron.Signal](v interface{}) ([][]S, error) {
+	fields, err := fieldsOf(reflect.TypeOf(v))
+	if err != nil {
+		return nil, err
+	}
+	val := reflect.ValueOf(v)
+
+	w := neuron.WidthOf[S]()
+	inputs := make([][]S, len(fields))
+	for i, f := range fields {
+		fv, ok := fieldValue(val, f)
+		if !ok {
+			// A nil message leaves all of its fields out.
+			inputs[i] = []S{}
+			continue
+		}
+		inputs[i] = encodeValue[S](w, fv)
+	}
+	return inputs, nil
+}
+
+// Decode sets the fields of the struct that v points to from the outputs of
+// the MOTOR neurons. Single values take the first output of their neuron, or
+// the zero value without any, while strings, slices and arrays take every
+// output that fits. It returns an error if an output doesn't fit in a string,
+// which only holds bytes.
+func Decode[S neuron.Signal](outputs [][]S, v interface{}) error {
+	ptr := reflect.ValueOf(v)
+	if ptr.Kind() != reflect.Pointer || ptr.IsNil() {
+		return fmt.Errorf("decode needs a non-nil pointer, not %T", v)
+	}
+	fields, err := fieldsOf(ptr.Type())
+	if err != nil {
+		return err
+	}
+	if len(outputs) != len(fields) {
+		return fmt.Errorf("%v has %d fields, but there are %d outputs", ptr.Elem().Type(), len(fields), len(outputs))
+	}
+
+	w := neuron.WidthOf[S]()
+	for i, f := range fields {
+		fv := ptr.Elem()
+		for _, index := range f.index {
+			if fv.Kind() == reflect.Pointer {
+				if fv.IsNil() {
+					fv.Set(reflect.New(fv.Type().Elem()))
+				}
+				fv = fv.Elem()
+			}
+			fv = fv.Field(index)
+		}
+		if err := decodeValue(w, outputs[i], fv); err != nil {
+			return fmt.Errorf("%s: %v", f.name, err)
+		}
+	}
+	return nil
+}
+
+// NumNeurons returns the number of neurons needed for a struct, a pointer to
+// one, or a reflect.Type of either.
+func NumNeurons(v interface{}) (int, error) {
+	t, ok := v.(reflect.Type)
+	if !ok {
+		t = reflect.TypeOf(v)
+	}
+	fields, err := fieldsOf(t)
+	return len(fields), err
+}
+
+// Configure sets the NumInputs and NumOutputs of the config from the structs
+// going into and coming out of the brains.
+func Configure(c *neuron.PlaygroundConfig, input, output interface{}) error {
+	numInputs, err := NumNeurons(input)
+	if err != nil {
+		return fmt.Errorf("input: %v", err)
+	}
+	numOutputs, err := NumNeurons(output)
+	if err != nil {
+		return fmt.Errorf("output: %v", err)
+	}
+	c.NumInputs = numInputs
+	c.NumOutputs = numOutputs
+	return nil
+}
+
+// Names returns the name of the field for each neuron, with nested fields
+// joined by dots.
+func Names(v interface{}) ([]string, error) {
+	t, ok := v.(reflect.Type)
+	if !ok {
+		t = reflect.TypeOf(v)
+	}
+	fields, err := fieldsOf(t)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = f.name
+	}
+	return names, nil
+}
+
+func fieldsOf(t reflect.Type) ([]field, error) {
+	if t == nil {
+		return nil, fmt.Errorf("can't encode nil")
+	}
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("can only encode structs, not %v", t)
+	}
+	return appendFields(nil, t, nil, "", make(map[reflect.Type]bool))
+}
+
+// appendFields adds the fields of the struct, with nested structs flattened
+// into theirs. seen holds the structs being flattened, since a struct that
+// contains itself would need endless neurons.
+func appendFields(fields []field, t reflect.Type, index []int, prefix string, seen map[reflect.Type]bool) ([]field, error) {
+	if seen[t] {
+		return nil, fmt.Errorf("%v contains itself", t)
+	}
+	seen[t] = true
+	defer delete(seen, t)
+
+	for _, i := range fieldOrder(t) {
+		sf := t.Field(i)
+		if !sf.IsExported() || sf.Tag.Get(TagName) == "-" || strings.HasPrefix(sf.Name, "XXX_") {
+			continue
+		}
+		if _, ok := sf.Tag.Lookup("protobuf_oneof"); ok {
+			return nil, fmt.Errorf("%v.%s is a oneof, which isn't supported", t, sf.Name)
+		}
+
+		path := append(append([]int{}, index...), i)
+		name := prefix + sf.Name
+		ft := sf.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if ft.Kind() == reflect.Struct {
+			var err error
+			if fields, err = appendFields(fields, ft, path, name+".", seen); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if !encodable(ft) {
+			return nil, fmt.Errorf("%v.%s has type %v, which can't be encoded", t, sf.Name, sf.Type)
+		}
+		fields = append(fields, field{index: path, name: name})
+	}
+	return fields, nil
+}
+
+// fieldOrder returns the indices of the struct fields, sorted by their
+// protobuf field numbers when there are any.
+func fieldOrder(t reflect.Type) []int {
+	order := make([]int, t.NumField())
+	numbers := make([]int, t.NumField())
+	for i := range order {
+		order[i] = i
+		numbers[i] = protoNumber(t.Field(i))
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return numbers[order[a]] < numbers[order[b]]
+	})
+	return order
+}
+
+// protoNumber is the field number from a tag like
+// `protobuf:"varint,2,opt,name=count,proto3"`, or 0 when there isn't one so
+// that plain structs keep their order.
+func protoNumber(sf reflect.StructField) int {
+	parts := strings.Split(sf.Tag.Get("protobuf"), ",")
+	if len(parts) < 2 {
+		return 0
+	}
+	number, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0
+	}
+	return number
+}
+
+func encodable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		return isScalar(t.Elem())
+	case reflect.String:
+		return true
+	}
+	return isScalar(t)
+}
+
+func isScalar(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// fieldValue follows the path to the field, and returns false if a nil
+// pointer is in the way.
+func fieldValue(val reflect.Value, f field) (reflect.Value, bool) {
+	for _, index := range f.index {
+		if val.Kind() == reflect.Pointer {
+			if val.IsNil() {
+				return val, false
+			}
+			val = val.Elem()
+		}
+		val = val.Field(index)
+	}
+	if val.Kind() == reflect.Pointer {
+		if val.IsNil() {
+			return val, false
+		}
+		val = val.Elem()
+	}
+	return val, true
+}
+
+func encodeValue[S neuron.Signal](w neuron.Width, val reflect.Value) []S {
+	switch val.Kind() {
+	case reflect.String:
+		str := val.String()
+		signals := make([]S, len(str))
+		for i := 0; i < len(str); i++ {
+			signals[i] = S(toWord(w, float64(str[i])))
+		}
+		return signals
+	case reflect.Slice, reflect.Array:
+		signals := make([]S, val.Len())
+		for i := range signals {
+			signals[i] = S(scalarWord(w, val.Index(i)))
+		}
+		return signals
+	}
+	return []S{S(scalarWord(w, val))}
+}
+
+func scalarWord(w neuron.Width, val reflect.Value) neuron.Word {
+	switch val.Kind() {
+	case reflect.Bool:
+		if val.Bool() {
+			return w.Max()
+		}
+		return 0
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return toWord(w, float64(val.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return toWord(w, float64(val.Uint()))
+	}
+	return toWord(w, val.Float())
+}
+
+// toWord scales the number by One and clamps it to the width.
+func toWord(w neuron.Width, x float64) neuron.Word {
+	x = math.Round(x * float64(w.One()))
+	if x <= 0 || math.IsNaN(x) {
+		return 0
+	}
+	if x >= float64(w.Max()) {
+		return w.Max()
+	}
+	return neuron.Word(x)
+}
+
+func decodeValue[S neuron.Signal](w neuron.Width, signals []S, val reflect.Value) error {
+	if val.Kind() == reflect.Pointer {
+		val.Set(reflect.New(val.Type().Elem()))
+		val = val.Elem()
+	}
+
+	switch val.Kind() {
+	case reflect.String:
+		str := make([]byte, len(signals))
+		for i, signal := range signals {
+			x := fromWord(w, neuron.Word(signal))
+			if x > math.MaxUint8 {
+				return fmt.Errorf("output %d is %d, which doesn't fit in a byte of a string", i, x)
+			}
+			str[i] = byte(x)
+		}
+		val.SetString(string(str))
+	case reflect.Slice:
+		slice := reflect.MakeSlice(val.Type(), len(signals), len(signals))
+		for i, signal := range signals {
+			setScalar(w, neuron.Word(signal), slice.Index(i))
+		}
+		val.Set(slice)
+	case reflect.Array:
+		val.Set(reflect.Zero(val.Type()))
+		for i := 0; i < val.Len() && i < len(signals); i++ {
+			setScalar(w, neuron.Word(signals[i]), val.Index(i))
+		}
+	default:
+		val.Set(reflect.Zero(val.Type()))
+		if len(signals) > 0 {
+			setScalar(w, neuron.Word(signals[0]), val)
+		}
+	}
+	return nil
+}
+
+func setScalar(w neuron.Width, x neuron.Word, val reflect.Value) {
+	switch val.Kind() {
+	case reflect.Bool:
+		val.SetBool(x != 0)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		val.SetInt(int64(fromWord(w, x)))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		val.SetUint(uint64(fromWord(w, x)))
+	default:
+		val.SetFloat(float64(x) / float64(w.One()))
+	}
+}
+
+// fromWord undoes the scaling by One, dropping any fraction.
+func fromWord(w neuron.Width, x neuron.Word) neuron.Word {
+	return x / w.One()
+}
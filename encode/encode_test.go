@@ -0,0 +1,164 @@
+package encode
+
+import (
+	"hackathon/sam/evolve/neuron"
+	"reflect"
+	"testing"
+)
+
+type point struct {
+	X, Y int
+}
+
+type testState struct {
+	Count   int
+	Ready   bool
+	Ratio   float32
+	Name    string
+	History []uint8
+	Pos     point
+	Target  *point
+	Skipped int `evolve:"-"`
+	hidden  int
+}
+
+// testMessage is shaped like the code protoc generates, with the fields
+// declared out of order.
+type testMessage struct {
+	state         struct{}
+	sizeCache     int32
+	unknownFields []byte
+
+	Score  int32   `protobuf:"varint,3,opt,name=score,proto3" json:"score,omitempty"`
+	Moves  []int32 `protobuf:"varint,1,rep,packed,name=moves,proto3" json:"moves,omitempty"`
+	Player *point  `protobuf:"bytes,2,opt,name=player,proto3" json:"player,omitempty"`
+}
+
+type oneofMessage struct {
+	Value interface{} `protobuf_oneof:"value"`
+}
+
+func TestEncode(t *testing.T) {
+	state := testState{
+		Count:   300,
+		Ready:   true,
+		Ratio:   2.6,
+		Name:    "hi",
+		History: []uint8{1, 2},
+		Pos:     point{X: -1, Y: 4},
+		Skipped: 7,
+		hidden:  8,
+	}
+	got, err := Encode[neuron.SignalType](state)
+	if err != nil {
+		t.Fatalf("Got error %v", err)
+	}
+	// Counts are clamped, negative numbers are 0 and the nil Target is empty.
+	want := [][]neuron.SignalType{{255}, {255}, {3}, {'h', 'i'}, {1, 2}, {0}, {4}, {}, {}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+
+	fixed, err := Encode[neuron.Fixed](&state)
+	if err != nil {
+		t.Fatalf("Got error %v", err)
+	}
+	one := neuron.Fixed(neuron.WidthOf[neuron.Fixed]().One())
+	if got, want := fixed[0], []neuron.Fixed{300 * one}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+	if got, want := fixed[2], []neuron.Fixed{neuron.Fixed(2.6*float64(one) + 0.5)}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	outputs := [][]neuron.SignalType{{5, 6}, {1}, {3}, {'o', 'k'}, {}, {7}, {8}, {9}, {}}
+	var got testState
+	if err := Decode(outputs, &got); err != nil {
+		t.Fatalf("Got error %v", err)
+	}
+	want := testState{
+		Count:   5,
+		Ready:   true,
+		Ratio:   3,
+		Name:    "ok",
+		History: []uint8{},
+		Pos:     point{X: 7, Y: 8},
+		Target:  &point{X: 9},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %+v, want %+v", got, want)
+	}
+
+	// Encoding what was decoded gives back the outputs.
+	if encoded, err := Encode[neuron.SignalType](got); err != nil || !reflect.DeepEqual(encoded[3], outputs[3]) {
+		t.Errorf("Got %v, %v, want %v", encoded, err, outputs)
+	}
+
+	if err := Decode(outputs[:2], &got); err == nil {
+		t.Errorf("Want an error for too few outputs")
+	}
+	if err := Decode(outputs, got); err == nil {
+		t.Errorf("Want an error for a struct that isn't a pointer")
+	}
+
+	// A wider signal can be more than a string can hold.
+	wide := [][]uint16{{}, {}, {}, {'o', 300}, {}, {}, {}, {}, {}}
+	if err := Decode(wide, &got); err == nil {
+		t.Errorf("Want an error for a string output over a byte")
+	}
+}
+
+func TestEncodeNullRune(t *testing.T) {
+	// False, zero and negative numbers are all the NullRune.
+	got, err := Encode[neuron.SignalType](point{X: -3, Y: 0})
+	if err != nil {
+		t.Fatalf("Got error %v", err)
+	}
+	if want := [][]neuron.SignalType{{neuron.NullRune}, {neuron.NullRune}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+}
+
+func TestEncodeProto(t *testing.T) {
+	msg := &testMessage{Score: 4, Moves: []int32{1, 2, 3}}
+	names, err := Names(msg)
+	if err != nil {
+		t.Fatalf("Got error %v", err)
+	}
+	if got, want := names, []string{"Moves", "Player.X", "Player.Y", "Score"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+
+	got, err := Encode[neuron.SignalType](msg)
+	if err != nil {
+		t.Fatalf("Got error %v", err)
+	}
+	if want := [][]neuron.SignalType{{1, 2, 3}, {}, {}, {4}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+
+	if _, err := NumNeurons(oneofMessage{}); err == nil {
+		t.Errorf("Want an error for a oneof")
+	}
+}
+
+func TestConfigure(t *testing.T) {
+	c := neuron.PlaygroundConfig{}
+	if err := Configure(&c, testState{}, reflect.TypeOf(testMessage{})); err != nil {
+		t.Fatalf("Got error %v", err)
+	}
+	if got, want := c.NumInputs, 9; got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+	if got, want := c.NumOutputs, 4; got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+
+	for _, v := range []interface{}{nil, 3, struct{ M map[int]int }{}} {
+		if err := Configure(&c, v, testState{}); err == nil {
+			t.Errorf("Want an error for %T", v)
+		}
+	}
+}
@@ -7,8 +7,8 @@ import (
 	"time"
 )
 
-func DefaultStockConfig() neuron.RunnerConfig {
-	return neuron.RunnerConfig{
+func DefaultStockConfig[S neuron.Signal]() neuron.RunnerConfig[S] {
+	return neuron.RunnerConfig[S]{
 		Generations: 1000,
 		Rounds:      10,
 
@@ -31,9 +31,14 @@ func DefaultStockConfig() neuron.RunnerConfig {
 				AddNeuron:  0.02,
 				AddSynapse: 0.05,
 
-				ChangeOp:  0.5,
-				SetSeed:   0.4,
-				UnsetSeed: 0.4,
+				ChangeOp:   0.5,
+				ChangeKind: 0.1,
+				SetSeed:    0.4,
+				UnsetSeed:  0.4,
+
+				ChangeTableEntry: 0.2,
+				ChangeThreshold:  0.05,
+				ChangeRefractory: 0.05,
 			},
 		},
 	}
@@ -54,8 +59,8 @@ func (d *DayTrader) CurrentState() [][]neuron.SignalType {
 	if newVal <= 0 {
 		newVal = 1
 	}
-	if newVal >= int(neuron.MaxSignal()) {
-		newVal = int(neuron.MaxSignal())
+	if newVal >= int(neuron.MaxSignal[neuron.SignalType]()) {
+		newVal = int(neuron.MaxSignal[neuron.SignalType]())
 	}
 	d.stockValues[d.minute] = neuron.SignalType(newVal)
 	return [][]neuron.SignalType{{d.stockValues[d.minute]}}
@@ -105,8 +110,8 @@ func (d *DayTrader) Fitness() neuron.ScoreType {
 }
 
 func StockSimulation() {
-	config := DefaultStockConfig()
-	config.NewGameFn = func() neuron.Game {
+	config := DefaultStockConfig[neuron.SignalType]()
+	config.NewGameFn = func() neuron.Game[neuron.SignalType] {
 		d := &DayTrader{
 			minute:      1,
 			stockValues: make([]neuron.SignalType, 250),
@@ -114,7 +119,7 @@ func StockSimulation() {
 			money:       1000,
 			sharesOwned: 0,
 		}
-		d.stockValues[0] = neuron.MaxSignal() / 2
+		d.stockValues[0] = neuron.MaxSignal[neuron.SignalType]() / 2
 		return d
 	}
 	runner := neuron.NewRunner(config)
@@ -122,14 +127,15 @@ func StockSimulation() {
 }
 */
 
+// Adder uses 16 bit signals so that the sum doesn't wrap around.
 type Adder struct {
-	inputs [][]neuron.SignalType
-	answer neuron.SignalType
-	output neuron.SignalType
+	inputs [][]uint16
+	answer uint16
+	output uint16
 	isOver bool
 }
 
-func (a *Adder) CurrentState() [][]neuron.SignalType {
+func (a *Adder) CurrentState() [][]uint16 {
 	a.isOver = true
 
 	for i := 0; i < len(a.inputs); i++ {
@@ -141,7 +147,7 @@ func (a *Adder) CurrentState() [][]neuron.SignalType {
 	return a.inputs
 }
 
-func (a *Adder) Update(signals [][]neuron.SignalType) {
+func (a *Adder) Update(signals [][]uint16) {
 	if len(signals) == 0 || len(signals[0]) == 0 {
 		a.output = 0
 	} else {
@@ -166,17 +172,17 @@ func (a *Adder) Fitness() neuron.ScoreType {
 func RunAdder() {
 	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
 
-	config := DefaultStockConfig()
+	config := DefaultStockConfig[uint16]()
 	config.PConf.NumInputs = 2
 	config.PConf.NumOutputs = 1
-	config.NewGameFn = func() neuron.Game {
+	config.NewGameFn = func() neuron.Game[uint16] {
 		a := &Adder{
-			inputs: make([][]neuron.SignalType, 2),
+			inputs: make([][]uint16, 2),
 		}
 
 		for i := 0; i < 2; i++ {
 			for ii := 0; ii < 2; ii++ {
-				a.inputs[i] = append(a.inputs[i], neuron.SignalType(rng.Intn(63)+1))
+				a.inputs[i] = append(a.inputs[i], uint16(rng.Intn(63)+1))
 			}
 		}
 		return a
@@ -268,10 +274,10 @@ func convert(input int) string {
 func RomanNumeralConverter() {
 	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
 
-	config := DefaultStockConfig()
+	config := DefaultStockConfig[neuron.SignalType]()
 	config.PConf.NumInputs = 1
 	config.PConf.NumOutputs = 1
-	config.NewGameFn = func() neuron.Game {
+	config.NewGameFn = func() neuron.Game[neuron.SignalType] {
 		return &RomanNumeral{
 			input:  rng.Intn(40), //3999),
 			output: make([]rune, 0),
@@ -346,10 +352,10 @@ func (a *VisualCortexAdder) Fitness() neuron.ScoreType {
 func RunVisualCortexAdder() {
 	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
 
-	config := DefaultStockConfig()
+	config := DefaultStockConfig[neuron.SignalType]()
 	config.PConf.NumInputs = 16
 	config.PConf.NumOutputs = 5
-	config.NewGameFn = func() neuron.Game {
+	config.NewGameFn = func() neuron.Game[neuron.SignalType] {
 		a := &VisualCortexAdder{
 			values: make([]int, rng.Intn(8)+2),
 		}
@@ -376,10 +382,10 @@ type HealthChecker struct {
 }
 
 func (h *HealthChecker) CurrentState() [][]neuron.SignalType {
-	state := neuron.MaxSignal()
+	state := neuron.MaxSignal[neuron.SignalType]()
 	if !h.isUp {
 		// Can't put in 0 because that's the NullRune.
-		state = neuron.MaxSignal() / 2
+		state = neuron.MaxSignal[neuron.SignalType]() / 2
 	}
 
 	return [][]neuron.SignalType{{state}}
@@ -453,18 +459,24 @@ func (h *HealthChecker) IsOver() bool {
 	return h.second >= 86400
 }
 
+// CarryPolicy starts every health check without the signals left over from the
+// last one, but stateful neurons still remember how the checks have gone.
+func (h *HealthChecker) CarryPolicy() neuron.CarryPolicy {
+	return neuron.CARRY_STATE
+}
+
 func (h *HealthChecker) Fitness() neuron.ScoreType {
 	return neuron.ScoreType(h.uptime)
 }
 
 func RunHealthChecker() {
-	config := DefaultStockConfig()
+	config := DefaultStockConfig[neuron.SignalType]()
 
 	config.Rounds = 1
 	config.PConf.NumInputs = 1
 	config.PConf.NumOutputs = 1
 
-	config.NewGameFn = func() neuron.Game {
+	config.NewGameFn = func() neuron.Game[neuron.SignalType] {
 		return &HealthChecker{}
 	}
 
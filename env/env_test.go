@@ -56,27 +56,27 @@ func TestDayTrader(t *testing.T) {
 
 func TestAdderFitness(t *testing.T) {
 	a := &Adder{
-		inputs: [][]neuron.SignalType{{3, 4}, {5, 6}}, // sum: 18
+		inputs: [][]uint16{{3, 4}, {5, 6}}, // sum: 18
 	}
 	a.CurrentState()
 
-	a.Update([][]neuron.SignalType{})
-	if got, want := a.output, neuron.SignalType(0); got != want {
+	a.Update([][]uint16{})
+	if got, want := a.output, uint16(0); got != want {
 		t.Errorf("Got %v, want %v", got, want)
 	}
 	if got, want := a.Fitness(), neuron.ScoreType(0); got != want {
 		t.Errorf("Got %v, want %v", got, want)
 	}
 
-	a.Update([][]neuron.SignalType{{48, 2}})
-	if got, want := a.output, neuron.SignalType(48); got != want {
+	a.Update([][]uint16{{48, 2}})
+	if got, want := a.output, uint16(48); got != want {
 		t.Errorf("Got %v, want %v", got, want)
 	}
 	if got, want := a.Fitness(), neuron.ScoreType(256*256-30*30); got != want {
 		t.Errorf("Got %v, want %v", got, want)
 	}
 
-	a.Update([][]neuron.SignalType{{18}})
+	a.Update([][]uint16{{18}})
 	if got, want := a.Fitness(), neuron.ScoreType(256*256); got != want {
 		t.Errorf("Got %v, want %v", got, want)
 	}
@@ -1,3 +1,3 @@
 module hackathon/sam/evolve
 
-go 1.15
+go 1.18
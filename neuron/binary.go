@@ -0,0 +1,517 @@
+package neuron
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"sort"
+)
+
+// BinaryMagic starts every binary genome, so that other files aren't mistaken
+// for one.
+const BinaryMagic = "NEVO"
+
+// BinarySourceMagic starts the conglomerate that an archive of genomes
+// shares.
+const BinarySourceMagic = "NEVS"
+
+// BinaryArchivedMagic starts a genome in an archive, which only refers to
+// the conglomerate written at the start of the archive.
+const BinaryArchivedMagic = "NEVA"
+
+// BinaryVersion is the version of the binary format written by this package.
+const BinaryVersion = 1
+
+const (
+	binaryHasSeed = 1 << iota
+	binaryHasTable
+)
+
+// MarshalBinary encodes the DNA along with the portion of its Source that it
+// references, which is every SENSE and MOTOR neuron plus its own inter
+// neurons and synapses. The format is a header with the magic, version and
+// signal width, then varints for everything else, and a CRC32 checksum of all
+// of it at the end.
+func (d *DNA[S]) MarshalBinary() ([]byte, error) {
+	return d.marshalBinary(false), nil
+}
+
+// marshalBinary encodes the DNA like MarshalBinary. An archived genome only
+// has the IDs of its synapses instead of its portion of the Source, since the
+// archive already has the whole thing.
+func (d *DNA[S]) marshalBinary(archived bool) []byte {
+	var buf bytes.Buffer
+	if archived {
+		buf.WriteString(BinaryArchivedMagic)
+	} else {
+		buf.WriteString(BinaryMagic)
+	}
+	w := WidthOf[S]()
+	writeUvarint(&buf, BinaryVersion)
+	writeUvarint(&buf, uint64(w.Bits))
+	writeUvarint(&buf, uint64(w.FracBits))
+
+	neuronIDs := sortedNeuronIDs(d)
+
+	// Ops are written once by name, and the neurons refer to their index.
+	opIndex := make(map[OperatorType]int)
+	opNames := make([]string, 0)
+	for _, neuronID := range neuronIDs {
+		op := d.Neurons[neuronID].Op
+		if _, ok := opIndex[op]; !ok {
+			opIndex[op] = len(opNames)
+			opNames = append(opNames, op.String())
+		}
+	}
+	writeUvarint(&buf, uint64(len(opNames)))
+	for _, name := range opNames {
+		writeUvarint(&buf, uint64(len(name)))
+		buf.WriteString(name)
+	}
+
+	if archived {
+		synIDs := sortedSynapseIDs(d.Synpases)
+		writeUvarint(&buf, uint64(len(synIDs)))
+		for _, synID := range synIDs {
+			writeUvarint(&buf, uint64(synID))
+		}
+	} else {
+		writeConglomerate(&buf, d.Source, func(id IDType) bool {
+			_, ok := d.Neurons[id]
+			return ok
+		}, d.Synpases)
+	}
+
+	writeUvarint(&buf, uint64(len(neuronIDs)))
+	for _, neuronID := range neuronIDs {
+		neuron := d.Neurons[neuronID]
+		flags := byte(0)
+		if neuron.HasSeed {
+			flags |= binaryHasSeed
+		}
+		if neuron.Table != nil {
+			flags |= binaryHasTable
+		}
+
+		writeUvarint(&buf, uint64(neuronID))
+		writeUvarint(&buf, uint64(opIndex[neuron.Op]))
+		writeUvarint(&buf, uint64(neuron.Kind))
+		buf.WriteByte(flags)
+		if neuron.HasSeed {
+			writeUvarint(&buf, uint64(neuron.Seed))
+		}
+		writeVarint(&buf, int64(neuron.Threshold))
+		writeUvarint(&buf, uint64(neuron.Refractory))
+		if neuron.Table != nil {
+			writeUvarint(&buf, uint64(len(neuron.Table)))
+			for _, entry := range neuron.Table {
+				writeUvarint(&buf, uint64(entry))
+			}
+		}
+	}
+
+	return appendChecksum(&buf)
+}
+
+// UnmarshalBinary replaces the DNA with one written by MarshalBinary. The DNA
+// gets a new Source holding only the portion of the conglomerate that was
+// written with it.
+func (d *DNA[S]) UnmarshalBinary(data []byte) error {
+	loaded, err := unmarshalBinary[S](data, nil)
+	if err != nil {
+		return err
+	}
+	*d = *loaded
+	return nil
+}
+
+// unmarshalBinary decodes a genome written by marshalBinary. An archived
+// genome uses the shared Source that was read at the start of its archive,
+// and any other genome gets a new one when shared is nil.
+func unmarshalBinary[S Signal](data []byte, shared *Conglomerate) (*DNA[S], error) {
+	magic := BinaryMagic
+	if shared != nil {
+		magic = BinaryArchivedMagic
+	}
+	r, err := openBinary(data, magic)
+	if err != nil {
+		return nil, fmt.Errorf("not a binary genome: %v", err)
+	}
+	w := WidthOf[S]()
+	if bits, fracBits := r.uvarint(), r.uvarint(); r.err == nil && (uint(bits) != w.Bits || uint(fracBits) != w.FracBits) {
+		return nil, fmt.Errorf("genome has %d bit signals with %d fraction bits, want %d with %d", bits, fracBits, w.Bits, w.FracBits)
+	}
+
+	ops := make([]OperatorType, r.count())
+	for i := range ops {
+		name := string(r.bytes(int(r.count())))
+		op, ok := LookupOperator(name)
+		if r.err == nil && !ok {
+			return nil, fmt.Errorf("unregistered operator %q", name)
+		}
+		ops[i] = op
+	}
+
+	var loaded *DNA[S]
+	if shared != nil {
+		loaded = NewDNA[S](shared)
+		numSynapses := r.count()
+		for i := uint64(0); i < numSynapses && r.err == nil; i++ {
+			synID := IDType(r.uvarint())
+			if r.err != nil {
+				break
+			}
+			if _, ok := shared.Synapses.idMap[synID]; !ok {
+				return nil, fmt.Errorf("synapse %d isn't in the source", synID)
+			}
+			loaded.AddSynapse(synID)
+		}
+	} else {
+		source, err := r.conglomerate()
+		if err != nil {
+			return nil, err
+		}
+		loaded = NewDNA[S](source)
+		for synID := range source.Synapses.idMap {
+			loaded.AddSynapse(synID)
+		}
+	}
+	source := loaded.Source
+
+	numNeurons := r.count()
+	for i := uint64(0); i < numNeurons && r.err == nil; i++ {
+		neuronID := IDType(r.uvarint())
+		opIndex := r.uvarint()
+		kind := NeuronKind(r.uvarint())
+		flags := r.readByte()
+		if r.err != nil {
+			break
+		}
+		if !source.hasNeuron(neuronID) {
+			return nil, fmt.Errorf("neuron %d isn't in the conglomerate", neuronID)
+		}
+		if opIndex >= uint64(len(ops)) {
+			return nil, fmt.Errorf("neuron %d has op index %d out of %d", neuronID, opIndex, len(ops))
+		}
+		if kind < 0 || int(kind) >= len(NeuronKinds) {
+			return nil, fmt.Errorf("neuron %d has unknown kind %d", neuronID, kind)
+		}
+
+		neuron := NewNeuron[S](ops[opIndex])
+		neuron.Kind = kind
+		if flags&binaryHasSeed != 0 {
+			neuron.SetSeed(S(r.uvarint()))
+		}
+		neuron.Threshold = int(r.varint())
+		neuron.Refractory = int(r.uvarint())
+		if flags&binaryHasTable != 0 {
+			if size := r.count(); r.err == nil && size != TableSize {
+				return nil, fmt.Errorf("neuron %d has a table of %d entries instead of %d", neuronID, size, TableSize)
+			}
+			neuron.Table = make([]S, TableSize)
+			for entry := range neuron.Table {
+				neuron.Table[entry] = S(r.uvarint())
+			}
+		}
+		loaded.Neurons[neuronID] = neuron
+	}
+
+	if r.err != nil {
+		return nil, fmt.Errorf("truncated binary genome: %v", r.err)
+	}
+	if r.r.Len() != 0 {
+		return nil, fmt.Errorf("binary genome has %d unexpected trailing bytes", r.r.Len())
+	}
+	return loaded, nil
+}
+
+// WriteGenomes writes the Source that the DNA all share, then each of them as
+// an archived genome that only refers to it, with every record prefixed by
+// its length. This is how whole generations are archived.
+func WriteGenomes[S Signal](w io.Writer, dnas []*DNA[S]) error {
+	if len(dnas) == 0 {
+		return nil
+	}
+	for i, dna := range dnas {
+		if dna.Source != dnas[0].Source {
+			return fmt.Errorf("genome #%d has a different Source than genome #0", i)
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	if err := writeRecord(bw, marshalSource(dnas[0].Source)); err != nil {
+		return err
+	}
+	for _, dna := range dnas {
+		if err := writeRecord(bw, dna.marshalBinary(true)); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// ReadGenomes reads every genome written by WriteGenomes until the end of the
+// reader. The genomes all share the Source read at the start, just like the
+// DNA that were written.
+func ReadGenomes[S Signal](r io.Reader) ([]*DNA[S], error) {
+	br := bufio.NewReader(r)
+	dnas := make([]*DNA[S], 0)
+	data, err := readRecord(br)
+	if errors.Is(err, io.EOF) {
+		return dnas, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("source: %v", err)
+	}
+	source, err := unmarshalSource(data)
+	if err != nil {
+		return nil, fmt.Errorf("source: %v", err)
+	}
+
+	for {
+		data, err := readRecord(br)
+		if errors.Is(err, io.EOF) {
+			return dnas, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("genome #%d: %v", len(dnas), err)
+		}
+
+		dna, err := unmarshalBinary[S](data, source)
+		if err != nil {
+			return nil, fmt.Errorf("genome #%d: %v", len(dnas), err)
+		}
+		dnas = append(dnas, dna)
+	}
+}
+
+// marshalSource encodes the whole conglomerate, along with the next IDs so
+// that none are handed out again.
+func marshalSource(c *Conglomerate) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(BinarySourceMagic)
+	writeUvarint(&buf, BinaryVersion)
+	writeConglomerate(&buf, c, func(IDType) bool { return true }, c.Synapses)
+	writeUvarint(&buf, uint64(c.nextNeuronID))
+	writeUvarint(&buf, uint64(c.Synapses.nextID))
+	return appendChecksum(&buf)
+}
+
+// unmarshalSource decodes a conglomerate written by marshalSource.
+func unmarshalSource(data []byte) (*Conglomerate, error) {
+	r, err := openBinary(data, BinarySourceMagic)
+	if err != nil {
+		return nil, fmt.Errorf("not a binary conglomerate: %v", err)
+	}
+	source, err := r.conglomerate()
+	if err != nil {
+		return nil, err
+	}
+	if nextNeuronID := IDType(r.uvarint()); nextNeuronID > source.nextNeuronID {
+		source.nextNeuronID = nextNeuronID
+	}
+	if nextSynapseID := IDType(r.uvarint()); nextSynapseID > source.Synapses.nextID {
+		source.Synapses.nextID = nextSynapseID
+	}
+	if r.err != nil {
+		return nil, fmt.Errorf("truncated binary conglomerate: %v", r.err)
+	}
+	if r.r.Len() != 0 {
+		return nil, fmt.Errorf("binary conglomerate has %d unexpected trailing bytes", r.r.Len())
+	}
+	return source, nil
+}
+
+// WriteGeneration archives every DNA in the playground in ID order.
+func (p *Playground[S]) WriteGeneration(w io.Writer) error {
+	ids := make([]IDType, 0, len(p.codes))
+	for id := range p.codes {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	dnas := make([]*DNA[S], len(ids))
+	for i, id := range ids {
+		dnas[i] = p.codes[id]
+	}
+	return WriteGenomes(w, dnas)
+}
+
+// writeConglomerate writes the neuron IDs of each type in index order, where
+// only the inter neurons that are kept are included, then the synapses in ID
+// order.
+func writeConglomerate(buf *bytes.Buffer, c *Conglomerate, keepInter func(IDType) bool, synapses *SynapseTracker) {
+	for _, nType := range NeuronTypes {
+		ids := make([]IDType, 0)
+		for index := 0; index < c.NeuronIDs[nType].Length(); index++ {
+			id := c.NeuronIDs[nType].GetID(index)
+			if nType != INTER || keepInter(id) {
+				ids = append(ids, id)
+			}
+		}
+		writeUvarint(buf, uint64(len(ids)))
+		for _, id := range ids {
+			writeUvarint(buf, uint64(id))
+		}
+	}
+
+	synIDs := sortedSynapseIDs(synapses)
+	writeUvarint(buf, uint64(len(synIDs)))
+	for _, synID := range synIDs {
+		syn := synapses.idMap[synID]
+		writeUvarint(buf, uint64(synID))
+		writeUvarint(buf, uint64(syn.src))
+		writeUvarint(buf, uint64(syn.dst))
+	}
+}
+
+// appendChecksum ends the buffer with a CRC32 checksum of everything in it.
+func appendChecksum(buf *bytes.Buffer) []byte {
+	checksum := make([]byte, 4)
+	binary.LittleEndian.PutUint32(checksum, crc32.ChecksumIEEE(buf.Bytes()))
+	buf.Write(checksum)
+	return buf.Bytes()
+}
+
+// openBinary checks the magic, checksum and version of the data, and returns
+// a reader for what's after the version.
+func openBinary(data []byte, magic string) (*binaryReader, error) {
+	if len(data) < len(magic)+4 || string(data[:len(magic)]) != magic {
+		return nil, fmt.Errorf("missing %q", magic)
+	}
+	body, checksum := data[:len(data)-4], data[len(data)-4:]
+	if got, want := crc32.ChecksumIEEE(body), binary.LittleEndian.Uint32(checksum); got != want {
+		return nil, fmt.Errorf("checksum mismatch: got %08x, want %08x", got, want)
+	}
+
+	r := &binaryReader{r: bytes.NewReader(body[len(magic):])}
+	if version := r.uvarint(); r.err == nil && version != BinaryVersion {
+		return nil, fmt.Errorf("unsupported binary version %d, want %d", version, BinaryVersion)
+	}
+	return r, nil
+}
+
+// writeRecord writes the data prefixed by its length.
+func writeRecord(w io.Writer, data []byte) error {
+	var prefix [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(prefix[:], uint64(len(data)))
+	if _, err := w.Write(prefix[:n]); err != nil {
+		return err
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+// readRecord reads data written by writeRecord, returning io.EOF if there
+// isn't any more.
+func readRecord(r *bufio.Reader) ([]byte, error) {
+	length, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	data := make([]byte, length)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func writeUvarint(buf *bytes.Buffer, x uint64) {
+	var tmp [binary.MaxVarintLen64]byte
+	buf.Write(tmp[:binary.PutUvarint(tmp[:], x)])
+}
+
+func writeVarint(buf *bytes.Buffer, x int64) {
+	var tmp [binary.MaxVarintLen64]byte
+	buf.Write(tmp[:binary.PutVarint(tmp[:], x)])
+}
+
+// binaryReader holds on to the first error, so decoding can read a whole
+// section before checking it.
+type binaryReader struct {
+	r   *bytes.Reader
+	err error
+}
+
+func (b *binaryReader) uvarint() uint64 {
+	if b.err != nil {
+		return 0
+	}
+	var x uint64
+	x, b.err = binary.ReadUvarint(b.r)
+	return x
+}
+
+func (b *binaryReader) varint() int64 {
+	if b.err != nil {
+		return 0
+	}
+	var x int64
+	x, b.err = binary.ReadVarint(b.r)
+	return x
+}
+
+// conglomerate reads one written by writeConglomerate.
+func (b *binaryReader) conglomerate() (*Conglomerate, error) {
+	source := NewConglomerate()
+	for _, nType := range NeuronTypes {
+		count := b.count()
+		for i := uint64(0); i < count && b.err == nil; i++ {
+			id := IDType(b.uvarint())
+			if source.hasNeuron(id) {
+				return nil, fmt.Errorf("duplicate neuron id %d", id)
+			}
+			source.TrackNeuron(nType, id)
+		}
+	}
+
+	numSynapses := b.count()
+	for i := uint64(0); i < numSynapses && b.err == nil; i++ {
+		synID, src, dst := IDType(b.uvarint()), IDType(b.uvarint()), IDType(b.uvarint())
+		if _, exists := source.Synapses.idMap[synID]; exists {
+			return nil, fmt.Errorf("duplicate synapse id %d", synID)
+		}
+		if !source.hasNeuron(src) || !source.hasNeuron(dst) {
+			return nil, fmt.Errorf("synapse %d connects unknown neurons src=%d,dst=%d", synID, src, dst)
+		}
+		source.Synapses.TrackSynapse(synID, src, dst)
+	}
+	return source, nil
+}
+
+// count reads the length of a list, which can't be more than the bytes left
+// since every item takes at least one.
+func (b *binaryReader) count() uint64 {
+	x := b.uvarint()
+	if b.err == nil && x > uint64(b.r.Len()) {
+		b.err = io.ErrUnexpectedEOF
+		return 0
+	}
+	return x
+}
+
+func (b *binaryReader) readByte() byte {
+	if b.err != nil {
+		return 0
+	}
+	var x byte
+	x, b.err = b.r.ReadByte()
+	return x
+}
+
+func (b *binaryReader) bytes(n int) []byte {
+	if b.err != nil {
+		return nil
+	}
+	if n > b.r.Len() {
+		b.err = io.ErrUnexpectedEOF
+		return nil
+	}
+	x := make([]byte, n)
+	_, b.err = io.ReadFull(b.r, x)
+	return x
+}
@@ -0,0 +1,165 @@
+package neuron
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func binaryTestDNA() *DNA[SignalType] {
+	d := SimpleTestDNA()
+	// An inter neuron that isn't part of the DNA shouldn't be written.
+	d.Source.AddInterNeuron(1)
+	interID := d.Source.AddInterNeuron(0)
+	d.AddNeuron(interID, SUBTRACT)
+	d.Neurons[interID].Refractory = 2
+	d.Neurons[interID].Threshold = AllInputs
+	for synID, syn := range d.Source.Synapses.idMap {
+		if syn.src == interID || syn.dst == interID {
+			d.AddSynapse(synID)
+		}
+	}
+
+	table := make([]SignalType, TableSize)
+	for i := range table {
+		table[i] = SignalType(i * 3)
+	}
+	d.Neurons[2].SetTable(table)
+	d.SetSeed(1, 200)
+	return d
+}
+
+func TestBinaryGenome(t *testing.T) {
+	d := binaryTestDNA()
+	data, err := d.MarshalBinary()
+	if err != nil {
+		t.Fatalf("Got error %v", err)
+	}
+	if got, want := string(data[:4]), BinaryMagic; got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+
+	loaded := &DNA[SignalType]{}
+	if err := loaded.UnmarshalBinary(data); err != nil {
+		t.Fatalf("Got error %v", err)
+	}
+	// Only the referenced inter neuron comes along, so the IDs match but the
+	// inter indices don't.
+	for id, neuron := range d.Neurons {
+		if !loaded.Neurons[id].IsEquiv(neuron) {
+			t.Errorf("Neuron %d: got %+v, want %+v", id, loaded.Neurons[id], neuron)
+		}
+	}
+	if !reflect.DeepEqual(loaded.Synpases.idMap, d.Synpases.idMap) {
+		t.Errorf("Got %v, want %v", loaded.Synpases.idMap, d.Synpases.idMap)
+	}
+	if got, want := loaded.Source.NeuronIDs[INTER].Length(), 1; got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+
+	inputs := [][]SignalType{{9, 4}, {2}}
+	if got, want := Flourish(loaded).Fire(inputs), Flourish(d).Fire(inputs); !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+
+	// Encoding is deterministic, which matters for archives.
+	again, _ := loaded.MarshalBinary()
+	if !bytes.Equal(again, data) {
+		t.Errorf("Re-encoding gave different bytes")
+	}
+}
+
+func TestBinaryGenomeErrors(t *testing.T) {
+	data, err := binaryTestDNA().MarshalBinary()
+	if err != nil {
+		t.Fatalf("Got error %v", err)
+	}
+
+	corrupt := make([]byte, len(data))
+	copy(corrupt, data)
+	corrupt[len(corrupt)/2] ^= 0xFF
+	if err := (&DNA[SignalType]{}).UnmarshalBinary(corrupt); err == nil || !strings.Contains(err.Error(), "checksum") {
+		t.Errorf("Want checksum error, got %v", err)
+	}
+
+	if err := (&DNA[SignalType]{}).UnmarshalBinary([]byte("not a genome")); err == nil {
+		t.Errorf("Want error for missing magic, got none")
+	}
+	if err := (&DNA[SignalType]{}).UnmarshalBinary(data[:len(data)-10]); err == nil {
+		t.Errorf("Want error for truncated data, got none")
+	}
+	if err := (&DNA[uint16]{}).UnmarshalBinary(data); err == nil || !strings.Contains(err.Error(), "bit signals") {
+		t.Errorf("Want signal width error, got %v", err)
+	}
+
+	short := binaryTestDNA()
+	short.Neurons[2].Table = short.Neurons[2].Table[:3]
+	data, _ = short.MarshalBinary()
+	if err := (&DNA[SignalType]{}).UnmarshalBinary(data); err == nil || !strings.Contains(err.Error(), "table") {
+		t.Errorf("Want table size error, got %v", err)
+	}
+}
+
+func TestWriteGeneration(t *testing.T) {
+	p := NewPlayground[SignalType](createTestPlayConfig())
+	p.InitDNA()
+
+	var buf bytes.Buffer
+	if err := p.WriteGeneration(&buf); err != nil {
+		t.Fatalf("Got error %v", err)
+	}
+	dnas, err := ReadGenomes[SignalType](&buf)
+	if err != nil {
+		t.Fatalf("Got error %v", err)
+	}
+	if got, want := len(dnas), len(p.codes); got != want {
+		t.Fatalf("Got %v, want %v", got, want)
+	}
+	for id, dna := range dnas {
+		if got, want := dna.PrettyPrint(), p.codes[id].PrettyPrint(); got != want {
+			t.Errorf("DNA %d: got %v, want %v", id, got, want)
+		}
+		// The genomes share the whole source, just like the playground's DNA.
+		if dna.Source != dnas[0].Source {
+			t.Errorf("DNA %d has its own source", id)
+		}
+	}
+	if got, want := dnas[0].Source.Synapses, p.source.Synapses; !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %+v, want %+v", got, want)
+	}
+	if got, want := dnas[0].Source.nextNeuronID, p.source.nextNeuronID; got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+
+	// Each genome only refers to the shared source, so the archive is smaller
+	// than the genomes written on their own.
+	var archive bytes.Buffer
+	if err := p.WriteGeneration(&archive); err != nil {
+		t.Fatalf("Got error %v", err)
+	}
+	standalone := 0
+	for _, dna := range p.codes {
+		data, _ := dna.MarshalBinary()
+		standalone += len(data)
+	}
+	if got, want := archive.Len(), standalone; got >= want {
+		t.Errorf("Got an archive of %d bytes, want less than %d", got, want)
+	}
+
+	other := SimpleTestDNA()
+	if err := WriteGenomes(&buf, []*DNA[SignalType]{p.codes[0], other}); err == nil {
+		t.Errorf("Want error for genomes with different sources, got none")
+	}
+
+	unknown := p.codes[0].DeepCopy()
+	unknown.Synpases.TrackSynapse(99, 0, 2)
+	if _, err := unmarshalBinary[SignalType](unknown.marshalBinary(true), p.source); err == nil || !strings.Contains(err.Error(), "synapse 99") {
+		t.Errorf("Want error for a synapse that isn't in the source, got %v", err)
+	}
+
+	dnas, err = ReadGenomes[SignalType](&bytes.Buffer{})
+	if err != nil || len(dnas) != 0 {
+		t.Errorf("Got %v with error %v, want nothing", dnas, err)
+	}
+}
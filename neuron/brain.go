@@ -161,6 +161,20 @@ func (s *SynapseTracker) AllDsts(src IDType) IDSet {
 	return dsts
 }
 
+// InputSlots returns the IDs of every synapse into the dst neuron, in the order
+// that their signals are given to it. Synapse IDs are never reused, so they
+// make for stable input slots on the dst neuron.
+func (s *SynapseTracker) InputSlots(dst IDType) []IDType {
+	slots := make([]IDType, 0)
+	for synID, syn := range s.idMap {
+		if syn.dst == dst {
+			slots = append(slots, synID)
+		}
+	}
+	sort.Ints(slots)
+	return slots
+}
+
 // FindID returns the synapse ID of the connection between the two input
 // neurons.
 func (s *SynapseTracker) FindID(src, dst IDType) (IDType, error) {
@@ -204,6 +218,9 @@ func (s *SynapseTracker) DeepCopy() *SynapseTracker {
 type Conglomerate struct {
 	NeuronIDs map[NeuronType]*IndexedIDs
 	Synapses  *SynapseTracker
+	// nextNeuronID is never used by any neuron, even after neurons are
+	// removed by Compact, so neuron IDs are never reused.
+	nextNeuronID IDType
 }
 
 // NewConglomerate inits a new conglomerate struct that's ready to be added to.
@@ -223,27 +240,36 @@ func NewConglomerate() *Conglomerate {
 // The number of inputs and outputs shouldn't change during the evolution
 // process.
 func (c *Conglomerate) AddVisionAndMotor(numInputs int, numOutputs int) {
-	id := 0
-	for ; id < numInputs; id++ {
-		c.NeuronIDs[SENSE].InsertID(id)
+	for i := 0; i < numInputs; i++ {
+		c.TrackNeuron(SENSE, c.nextNeuronID)
 	}
 
-	for ; id < numInputs+numOutputs; id++ {
-		c.NeuronIDs[MOTOR].InsertID(id)
+	for i := 0; i < numOutputs; i++ {
+		id := c.TrackNeuron(MOTOR, c.nextNeuronID)
 		for v := 0; v < numInputs; v++ {
-			c.Synapses.AddNewSynapse(v, id)
+			c.Synapses.AddNewSynapse(c.NeuronIDs[SENSE].GetID(v), id)
 		}
 	}
 }
 
+// TrackNeuron adds a neuron of the type at the ID, which must not already be
+// in use.
+func (c *Conglomerate) TrackNeuron(nType NeuronType, id IDType) IDType {
+	c.NeuronIDs[nType].InsertID(id)
+	// Update the nextNeuronID to always be an unused number.
+	if id >= c.nextNeuronID {
+		c.nextNeuronID = id + 1
+	}
+	return id
+}
+
 // AddInterNeuron adds a neuron along a synapse. This means that if neuron #1
 // has a synapse to neuron #2, then neuron #3 will be added in between 1 and 2,
 // creating synapses from 1->3 and 3->2, in addition to leaving the original
 // synapse from 1->2.
 func (c *Conglomerate) AddInterNeuron(synID IDType) IDType {
 	syn := c.Synapses.idMap[synID]
-	newID := c.NeuronIDs[SENSE].Length() + c.NeuronIDs[MOTOR].Length() + c.NeuronIDs[INTER].Length()
-	c.NeuronIDs[INTER].InsertID(newID)
+	newID := c.TrackNeuron(INTER, c.nextNeuronID)
 	c.Synapses.AddNewSynapse(syn.src, newID)
 	c.Synapses.AddNewSynapse(newID, syn.dst)
 	return newID
@@ -264,49 +290,49 @@ func (c *Conglomerate) GetNeuronType(id IDType) NeuronType {
 // brain. The DNA is a subset of the Conglomerate, so it can never have neurons
 // or synapses that aren't in the Conglomerate. It also contains actual Neuron
 // references, which have ops and seeds.
-type DNA struct {
+type DNA[S Signal] struct {
 	Source   *Conglomerate
-	Neurons  map[IDType]*Neuron
+	Neurons  map[IDType]*Neuron[S]
 	Synpases *SynapseTracker
 }
 
 // NewDNA initializes a new DNA struct, pointing to its source of IDs which is
 // a Conglomerate.
-func NewDNA(source *Conglomerate) *DNA {
-	return &DNA{
+func NewDNA[S Signal](source *Conglomerate) *DNA[S] {
+	return &DNA[S]{
 		Source:   source,
-		Neurons:  make(map[IDType]*Neuron),
+		Neurons:  make(map[IDType]*Neuron[S]),
 		Synpases: NewSynapseTracker(),
 	}
 }
 
-func (d *DNA) AddNeuron(id IDType, op OperatorType) {
-	d.Neurons[id] = NewNeuron(op)
+func (d *DNA[S]) AddNeuron(id IDType, op OperatorType) {
+	d.Neurons[id] = NewNeuron[S](op)
 }
 
-func (d *DNA) SetNeuron(id IDType, neuron *Neuron) {
+func (d *DNA[S]) SetNeuron(id IDType, neuron *Neuron[S]) {
 	d.Neurons[id] = neuron.Copy()
 }
 
-func (d *DNA) AddSynapse(id IDType) {
+func (d *DNA[S]) AddSynapse(id IDType) {
 	syn := d.Source.Synapses.idMap[id]
 	d.Synpases.TrackSynapse(id, syn.src, syn.dst)
 }
 
-func (d *DNA) RemoveSynapse(id IDType) {
+func (d *DNA[S]) RemoveSynapse(id IDType) {
 	d.Synpases.RemoveSynapse(id)
 }
 
-func (d *DNA) SetSeed(id IDType, seed SignalType) {
+func (d *DNA[S]) SetSeed(id IDType, seed S) {
 	d.Neurons[id].SetSeed(seed)
 }
 
-func (d *DNA) RemoveSeed(id IDType) {
+func (d *DNA[S]) RemoveSeed(id IDType) {
 	d.Neurons[id].RemoveSeed()
 }
 
-func (src *DNA) DeepCopy() *DNA {
-	dst := NewDNA(src.Source)
+func (src *DNA[S]) DeepCopy() *DNA[S] {
+	dst := NewDNA[S](src.Source)
 	for neuronID, neuron := range src.Neurons {
 		dst.Neurons[neuronID] = neuron.Copy()
 	}
@@ -314,18 +340,25 @@ func (src *DNA) DeepCopy() *DNA {
 	return dst
 }
 
+// Cost adds up the neuron, op and synapse costs of everything in the DNA. Ops
+// aren't charged for TABLE neurons, which don't use them.
+func (d *DNA[S]) Cost(c CostConfig) float32 {
+	cost := c.Synapse * float32(len(d.Synpases.idMap))
+	for _, neuron := range d.Neurons {
+		cost += c.Neuron
+		if neuron.Kind != TABLE {
+			cost += c.Ops[neuron.Op]
+		}
+	}
+	return cost
+}
+
 // PrettyPrint returns a formatted string of all neurons and synapses in the
 // DNA, which is useful for debugging.
-func (d *DNA) PrettyPrint() string {
+func (d *DNA[S]) PrettyPrint() string {
 	var sb strings.Builder
 
 	for _, nType := range NeuronTypes {
-		nTypeChar := "I"
-		if nType == SENSE {
-			nTypeChar = "V"
-		} else if nType == MOTOR {
-			nTypeChar = "M"
-		}
 		for index := 0; index < d.Source.NeuronIDs[nType].Length(); index++ {
 			neuronID := d.Source.NeuronIDs[nType].GetID(index)
 			neuron, ok := d.Neurons[neuronID]
@@ -333,10 +366,21 @@ func (d *DNA) PrettyPrint() string {
 				continue
 			}
 
-			sb.WriteString(fmt.Sprintf("%d (%s%d) = op%d", neuronID, nTypeChar, index, neuron.Op))
+			sb.WriteString(fmt.Sprintf("%d (%s) = op%d", neuronID, neuronName(nType, index), neuron.Op))
 			if neuron.HasSeed {
 				sb.WriteString(fmt.Sprintf(" <%d>", neuron.Seed))
 			}
+			if neuron.Kind != PURE {
+				sb.WriteString(fmt.Sprintf(" {%v}", neuron.Kind))
+			}
+			if neuron.Threshold == AllInputs {
+				sb.WriteString(" t*")
+			} else if neuron.Threshold != DefaultThreshold {
+				sb.WriteString(fmt.Sprintf(" t%d", neuron.Threshold))
+			}
+			if neuron.Refractory != 0 {
+				sb.WriteString(fmt.Sprintf(" r%d", neuron.Refractory))
+			}
 
 			sortedDstIDs := make([]IDType, 0)
 			for dst := range d.Synpases.AllDsts(neuronID) {
@@ -356,41 +400,138 @@ func (d *DNA) PrettyPrint() string {
 
 const NullRune = 0
 
-type brainOutput struct {
-	signalString []SignalType
+// SenseSlot is the input slot of external signals into SENSE neurons, which
+// comes before any synapse.
+const SenseSlot IDType = -1
+
+// pendingSignal is a signal waiting to be used by a neuron, along with the
+// input slot (synapse ID) it arrived on.
+type pendingSignal[S Signal] struct {
+	slot IDType
+	sig  S
+}
+
+type brainOutput[S Signal] struct {
+	signalString []S
 	isTerminated bool
 }
 
 // Brain docs
-type Brain struct {
-	dna            *DNA
-	pendingSignals map[IDType][]SignalType
+type Brain[S Signal] struct {
+	dna *DNA[S]
+	// pendingSignals are kept in input slot order, so that ordered operators
+	// see their inputs the same way every time.
+	pendingSignals map[IDType][]pendingSignal[S]
 	// outputSignals is a map instead of slice to tell which motor neurons have
 	// received and set an output.
-	outputSignals []brainOutput
-}
+	outputSignals []brainOutput[S]
+	// state holds the memory of stateful neurons across steps, and across
+	// calls to Fire unless the CarryPolicy drops it.
+	state map[IDType]S
+
+	// numSlots is the number of input slots into each neuron, for neurons that
+	// wait on AllInputs.
+	numSlots map[IDType]int
+	// steps counts every step the brain has taken, so restUntil can hold the
+	// first step each resting neuron can fire again.
+	steps     int
+	restUntil map[IDType]int
+
+	// firings counts every time a neuron fired, for the sake of costs.
+	firings int
+
+	// stepBudget is the most steps to take during each Fire, where 0 means the
+	// DefaultStepBudget.
+	stepBudget int
+	// carry decides what's kept from one Fire to the next.
+	carry CarryPolicy
+
+	// tracer is nil unless the brain is being traced.
+	tracer Tracer[S]
+}
+
+// Flourish grows a brain from the DNA. The DNA shouldn't change while the
+// brain is in use.
+func Flourish[S Signal](dna *DNA[S]) *Brain[S] {
+	numSlots := make(map[IDType]int, len(dna.Neurons))
+	for _, syn := range dna.Synpases.idMap {
+		numSlots[syn.dst]++
+	}
+	for i := 0; i < dna.Source.NeuronIDs[SENSE].Length(); i++ {
+		// External input has a slot of its own.
+		numSlots[dna.Source.NeuronIDs[SENSE].GetID(i)]++
+	}
 
-func Flourish(dna *DNA) *Brain {
-	return &Brain{
+	return &Brain[S]{
 		dna:            dna,
-		pendingSignals: make(map[IDType][]SignalType, len(dna.Neurons)),
-		outputSignals:  make([]brainOutput, dna.Source.NeuronIDs[MOTOR].Length()),
+		pendingSignals: make(map[IDType][]pendingSignal[S], len(dna.Neurons)),
+		outputSignals:  make([]brainOutput[S], dna.Source.NeuronIDs[MOTOR].Length()),
+		state:          make(map[IDType]S),
+		numSlots:       numSlots,
+		restUntil:      make(map[IDType]int),
 	}
 }
 
-// [][]SignalType can come from a single proto message in the future.
-func (b *Brain) Fire(inputs [][]SignalType) [][]SignalType {
-	inputStringIndex := 0
+// Firings returns the number of times any neuron has fired since the brain
+// was flourished.
+func (b *Brain[S]) Firings() int {
+	return b.firings
+}
 
-	// Cut off firing once it's very likely the output won't be generated.
-	for step := 0; step < 100; step++ {
+// DefaultStepBudget is the most steps a brain takes during one Fire, unless
+// it's given a different budget. Past that, it's very likely the output won't
+// be generated.
+const DefaultStepBudget = 100
+
+// FireResult has the outputs of a Fire along with how it went.
+type FireResult[S Signal] struct {
+	// Outputs has a signal string for each MOTOR neuron, which is empty unless
+	// the output was terminated.
+	Outputs [][]S
+	// Terminated tells apart a MOTOR that gave an empty answer from one that
+	// never finished.
+	Terminated []bool
+	// Steps is the number of steps taken during this Fire.
+	Steps int
+	// Exhausted is true if the step budget ran out before every output was
+	// terminated.
+	Exhausted bool
+	// Pending is the number of signals left waiting at the end of the Fire,
+	// whether or not the CarryPolicy keeps them for the next one.
+	Pending int
+}
+
+// SetStepBudget changes the most steps the brain takes during each Fire. A
+// budget of 0 or less goes back to the DefaultStepBudget.
+func (b *Brain[S]) SetStepBudget(budget int) {
+	b.stepBudget = budget
+}
+
+// [][]S can come from a single proto message in the future.
+func (b *Brain[S]) Fire(inputs [][]S) [][]S {
+	return b.FireResult(inputs).Outputs
+}
+
+// FireResult fires the brain like Fire, and says how it went.
+func (b *Brain[S]) FireResult(inputs [][]S) FireResult[S] {
+	budget := b.stepBudget
+	if budget <= 0 {
+		budget = DefaultStepBudget
+	}
+
+	result := FireResult[S]{
+		Exhausted: true,
+	}
+	inputStringIndex := 0
+	for result.Steps < budget {
 		// If there are any input signals left, add them to pendingSignals.
 		for visionIndex, inputString := range inputs {
-			if inputStringIndex > len(inputString) {
+			// Inputs without a vision neuron to go to are ignored.
+			if visionIndex >= b.dna.Source.NeuronIDs[SENSE].Length() || inputStringIndex > len(inputString) {
 				continue
 			}
 
-			var inputSignal SignalType
+			var inputSignal S
 			if inputStringIndex < len(inputString) {
 				inputSignal = inputString[inputStringIndex]
 			} else if inputStringIndex == len(inputString) {
@@ -398,11 +539,12 @@ func (b *Brain) Fire(inputs [][]SignalType) [][]SignalType {
 				// more input will be coming on this action.
 				inputSignal = NullRune
 			}
-			b.addPendingSignal(b.dna.Source.NeuronIDs[SENSE].GetID(visionIndex), inputSignal)
+			b.addPendingSignal(b.dna.Source.NeuronIDs[SENSE].GetID(visionIndex), SenseSlot, inputSignal)
 		}
 		inputStringIndex++
 
 		b.stepFunction()
+		result.Steps++
 
 		// Check if all of the output is ready to be returned.
 		allTerminated := true
@@ -412,48 +554,71 @@ func (b *Brain) Fire(inputs [][]SignalType) [][]SignalType {
 			}
 		}
 		if allTerminated {
+			result.Exhausted = false
 			break
 		}
 	}
 
-	outputs := make([][]SignalType, len(b.outputSignals))
+	result.Outputs = make([][]S, len(b.outputSignals))
+	result.Terminated = make([]bool, len(b.outputSignals))
 	for motorIndex, brainOutput := range b.outputSignals {
 		// Only terminated outputs are returned.
+		result.Terminated[motorIndex] = brainOutput.isTerminated
 		if brainOutput.isTerminated {
-			outputs[motorIndex] = make([]SignalType, len(brainOutput.signalString))
-			copy(outputs[motorIndex], brainOutput.signalString)
+			result.Outputs[motorIndex] = make([]S, len(brainOutput.signalString))
+			copy(result.Outputs[motorIndex], brainOutput.signalString)
 		} else {
-			outputs[motorIndex] = make([]SignalType, 0)
+			result.Outputs[motorIndex] = make([]S, 0)
 		}
 	}
+	for _, pending := range b.pendingSignals {
+		result.Pending += len(pending)
+	}
 
 	// Clear the output after it's used to make way for a new action.
-	b.outputSignals = make([]brainOutput, b.dna.Source.NeuronIDs[MOTOR].Length())
+	b.outputSignals = make([]brainOutput[S], b.dna.Source.NeuronIDs[MOTOR].Length())
+	b.carryOver()
 
-	return outputs
+	return result
 }
 
-func (b *Brain) stepFunction() {
+func (b *Brain[S]) stepFunction() {
 	// Create a separate map that will be merged with pendingSignals after all
 	// firing is done. This avoids a race condition where a synapse would add
 	// a pending signal to the map and then be cleared later if that neuron fires
 	// too.
-	nextPending := make(map[IDType][]SignalType, len(b.dna.Neurons))
+	nextPending := make(map[IDType][]pendingSignal[S], len(b.dna.Neurons))
 
-	for neuronID, inputs := range b.pendingSignals {
-		numInputs := len(inputs)
-		if b.dna.Neurons[neuronID].HasSeed {
-			numInputs++
+	var trace *StepTrace[S]
+	if b.tracer != nil {
+		trace = &StepTrace[S]{
+			Step:  b.steps,
+			Fired: make([]FiringTrace[S], 0),
 		}
+	}
 
-		// Neurons fire when they have at least 2 signals.
-		if numInputs < 2 {
+	for neuronID, pending := range b.pendingSignals {
+		neuron := b.dna.Neurons[neuronID]
+		if !b.isReady(neuronID, neuron, pending) {
 			continue
 		}
+		b.restUntil[neuronID] = b.steps + 1 + neuron.Refractory
+		b.firings++
 
-		neuron := b.dna.Neurons[neuronID]
-		output := neuron.Fire(inputs)
-		// fmt.Printf("firing neuron %d %+v with inputs %v and got output: %d\n", neuronID, neuron, inputs, output)
+		inputs := make([]S, len(pending))
+		for i, p := range pending {
+			inputs[i] = p.sig
+		}
+
+		var output S
+		if neuron.IsStateful() {
+			output, b.state[neuronID] = neuron.Step(inputs, b.state[neuronID])
+		} else {
+			output = neuron.Fire(inputs)
+		}
+		if trace != nil {
+			trace.Fired = append(trace.Fired, FiringTrace[S]{Neuron: neuronID, Inputs: neuron.operands(inputs), Output: output})
+		}
 
 		// Clear this neuron's pending signals now that it has fired.
 		// It's okay to edit the underlying map while iterating.
@@ -466,28 +631,94 @@ func (b *Brain) stepFunction() {
 					// A value of 0 is the termination character to cease listening for
 					// output on this neuron.
 					b.outputSignals[motorIndex].isTerminated = true
+					if trace != nil {
+						trace.Terminated = append(trace.Terminated, motorIndex)
+					}
 				} else {
 					b.outputSignals[motorIndex].signalString = append(b.outputSignals[motorIndex].signalString, output)
 				}
 			}
-			// fmt.Printf("output signals: %v+\n", b.outputSignals)
 		}
 
-		// Queue up signal for all downstream neurons.
-		for dst := range b.dna.Synpases.AllDsts(neuronID) {
-			nextPending[dst] = append(nextPending[dst], output)
+		// Queue up signal for all downstream neurons, in the input slot of the
+		// synapse it travels along.
+		for synID := range b.dna.Synpases.srcMap[neuronID] {
+			dst := b.dna.Synpases.idMap[synID].dst
+			nextPending[dst] = append(nextPending[dst], pendingSignal[S]{slot: synID, sig: output})
 		}
 	}
 
+	b.steps++
+
 	// Merge in nextPending now that the step is over.
 	for neuronID, signals := range nextPending {
-		for _, sig := range signals {
-			// fmt.Printf("new pending signal %d for %d\n", sig, neuronID)
-			b.addPendingSignal(neuronID, sig)
+		for _, p := range signals {
+			b.addPendingSignal(neuronID, p.slot, p.sig)
 		}
 	}
+
+	if trace != nil {
+		b.finishTrace(trace)
+	}
 }
 
-func (b *Brain) addPendingSignal(neuronID IDType, sig SignalType) {
-	b.pendingSignals[neuronID] = append(b.pendingSignals[neuronID], sig)
+// finishTrace puts the trace in a stable order, adds the leftover signals and
+// sends it to the tracer.
+func (b *Brain[S]) finishTrace(trace *StepTrace[S]) {
+	sort.Slice(trace.Fired, func(i, j int) bool {
+		return trace.Fired[i].Neuron < trace.Fired[j].Neuron
+	})
+	sort.Ints(trace.Terminated)
+
+	trace.Pending = make(map[IDType][]S, len(b.pendingSignals))
+	for neuronID, pending := range b.pendingSignals {
+		sigs := make([]S, len(pending))
+		for i, p := range pending {
+			sigs[i] = p.sig
+		}
+		trace.Pending[neuronID] = sigs
+	}
+	b.tracer.TraceStep(*trace)
+}
+
+// isReady returns true if the neuron has enough pending signals to reach its
+// threshold, and isn't resting from its last firing.
+func (b *Brain[S]) isReady(neuronID IDType, neuron *Neuron[S], pending []pendingSignal[S]) bool {
+	if b.steps < b.restUntil[neuronID] {
+		return false
+	}
+
+	if neuron.Threshold == AllInputs {
+		// The pending signals are in slot order, so each new slot is a change
+		// from the previous signal.
+		delivered := 0
+		for i, p := range pending {
+			if i == 0 || p.slot != pending[i-1].slot {
+				delivered++
+			}
+		}
+		return delivered >= b.numSlots[neuronID]
+	}
+
+	numInputs := len(pending)
+	if neuron.HasSeed {
+		numInputs++
+	}
+	return numInputs >= neuron.Threshold
+}
+
+// addPendingSignal inserts the signal after any others in the same or earlier
+// slots. Signals that waited from previous steps stay ahead of new ones in the
+// same slot.
+func (b *Brain[S]) addPendingSignal(neuronID IDType, slot IDType, sig S) {
+	pending := b.pendingSignals[neuronID]
+	index := len(pending)
+	for index > 0 && pending[index-1].slot > slot {
+		index--
+	}
+
+	pending = append(pending, pendingSignal[S]{})
+	copy(pending[index+1:], pending[index:])
+	pending[index] = pendingSignal[S]{slot: slot, sig: sig}
+	b.pendingSignals[neuronID] = pending
 }
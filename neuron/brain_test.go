@@ -7,11 +7,11 @@ import (
 )
 
 // Two vision neurons pointing at a motor neuron.
-func SimpleTestDNA() *DNA {
+func SimpleTestDNA() *DNA[SignalType] {
 	c := NewConglomerate()
 	c.AddVisionAndMotor(2, 1)
 
-	d := NewDNA(c)
+	d := NewDNA[SignalType](c)
 	d.AddNeuron(0, OR)
 	d.SetSeed(0, 0)
 	d.AddNeuron(1, OR)
@@ -187,17 +187,17 @@ func TestBrainStep(t *testing.T) {
 	c.NeuronIDs[INTER].InsertID(1)
 	c.Synapses.AddNewSynapse(0, 1)
 
-	d := NewDNA(c)
+	d := NewDNA[SignalType](c)
 	d.AddNeuron(0, OR)
 	d.SetSeed(0, 1)
 	d.AddNeuron(1, FALSIFY)
 	d.AddSynapse(0)
 	b := Flourish(d)
 
-	b.addPendingSignal(0, SignalType(2))
+	b.addPendingSignal(0, SenseSlot, SignalType(2))
 
-	wantMap := make(map[IDType][]SignalType, 2)
-	wantMap[0] = []SignalType{2}
+	wantMap := make(map[IDType][]pendingSignal[SignalType], 2)
+	wantMap[0] = []pendingSignal[SignalType]{{slot: SenseSlot, sig: 2}}
 	if !reflect.DeepEqual(wantMap, b.pendingSignals) {
 		t.Errorf("Want %v, got %v", wantMap, b.pendingSignals)
 	}
@@ -205,7 +205,7 @@ func TestBrainStep(t *testing.T) {
 	b.stepFunction()
 
 	delete(wantMap, 0)
-	wantMap[1] = []SignalType{3}
+	wantMap[1] = []pendingSignal[SignalType]{{slot: 0, sig: 3}}
 	if !reflect.DeepEqual(wantMap, b.pendingSignals) {
 		t.Errorf("Want %v, got %v", wantMap, b.pendingSignals)
 	}
@@ -222,6 +222,55 @@ func TestBrainFire(t *testing.T) {
 	if got, want := b.Fire([][]SignalType{{1}, {2}}), [][]SignalType{{3}}; !reflect.DeepEqual(got, want) {
 		t.Errorf("Want %v, got %v", want, got)
 	}
+	// Both vision neurons fire twice, once for the input and once for the null,
+	// and the motor fires with each of their outputs.
+	if got, want := b.Firings(), 6; got != want {
+		t.Errorf("Want %v, got %v", want, got)
+	}
+}
+
+func TestBrainFireResult(t *testing.T) {
+	b := Flourish(SimpleTestDNA())
+	want := FireResult[SignalType]{
+		Outputs:    [][]SignalType{{3}},
+		Terminated: []bool{true},
+		Steps:      3,
+	}
+	if got := b.FireResult([][]SignalType{{1}, {2}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Want %+v, got %+v", want, got)
+	}
+
+	// Running out of steps leaves the signals for the motor pending.
+	b.SetStepBudget(1)
+	want = FireResult[SignalType]{
+		Outputs:    [][]SignalType{{}},
+		Terminated: []bool{false},
+		Steps:      1,
+		Exhausted:  true,
+		Pending:    2,
+	}
+	if got := b.FireResult([][]SignalType{{1}, {2}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Want %+v, got %+v", want, got)
+	}
+}
+
+func TestDNACost(t *testing.T) {
+	d := SimpleTestDNA()
+	d.Neurons[2].SetTable(make([]SignalType, TableSize))
+
+	costs := CostConfig{
+		Neuron:  1,
+		Synapse: 0.5,
+		Ops:     map[OperatorType]float32{OR: 2},
+	}
+	// Three neurons and two synapses, where the TABLE neuron doesn't pay for
+	// its op.
+	if got, want := d.Cost(costs), float32(3+1+4); got != want {
+		t.Errorf("Want %v, got %v", want, got)
+	}
+	if got, want := d.Cost(CostConfig{}), float32(0); got != want {
+		t.Errorf("Want %v, got %v", want, got)
+	}
 }
 
 // Create a circular brain that won't ever output to test if Fire will
@@ -235,7 +284,7 @@ func TestCircularBrainFiring(t *testing.T) {
 	syn43 := c.Synapses.AddNewSynapse(4, 3)
 	syn54 := c.Synapses.AddNewSynapse(5, 4)
 
-	d := NewDNA(c)
+	d := NewDNA[SignalType](c)
 	for neuronID := 0; neuronID < 6; neuronID++ {
 		d.AddNeuron(neuronID, OR)
 		if neuronID == 2 {
@@ -259,3 +308,146 @@ func TestCircularBrainFiring(t *testing.T) {
 		t.Errorf("Want %v, got %v", want, got)
 	}
 }
+
+func TestBrainFireWideSignals(t *testing.T) {
+	c := NewConglomerate()
+	c.AddVisionAndMotor(2, 1)
+
+	d := NewDNA[uint16](c)
+	d.AddNeuron(0, OR)
+	d.SetSeed(0, 0)
+	d.AddNeuron(1, OR)
+	d.SetSeed(1, 0)
+	d.AddNeuron(2, ADD)
+	d.AddSynapse(0)
+	d.AddSynapse(1)
+
+	// The sum would wrap around with byte signals.
+	b := Flourish(d)
+	if got, want := b.Fire([][]uint16{{200}, {300}}), [][]uint16{{500}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Want %v, got %v", want, got)
+	}
+}
+
+func TestPendingSignalOrder(t *testing.T) {
+	b := Flourish(SimpleTestDNA())
+	b.addPendingSignal(2, 1, 10)
+	b.addPendingSignal(2, 0, 20)
+	b.addPendingSignal(2, 1, 30)
+	b.addPendingSignal(2, SenseSlot, 40)
+
+	want := []pendingSignal[SignalType]{{SenseSlot, 40}, {0, 20}, {1, 10}, {1, 30}}
+	if got := b.pendingSignals[2]; !reflect.DeepEqual(got, want) {
+		t.Errorf("Want %v, got %v", want, got)
+	}
+}
+
+func TestBrainFireOrderedOperator(t *testing.T) {
+	d := SimpleTestDNA()
+	d.Neurons[2].Op = SUBTRACT
+
+	// V0 is on synapse 0 and V1 is on synapse 1, so the motor neuron always
+	// subtracts the second input from the first.
+	for i := 0; i < 10; i++ {
+		b := Flourish(d)
+		if got, want := b.Fire([][]SignalType{{9}, {4}}), [][]SignalType{{5}}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("Want %v, got %v", want, got)
+		}
+	}
+
+	if got, want := d.Synpases.InputSlots(2), []IDType{0, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Want %v, got %v", want, got)
+	}
+}
+
+func TestBrainStatefulAcrossFires(t *testing.T) {
+	d := SimpleTestDNA()
+	d.Neurons[2].Kind = MEMORY
+	b := Flourish(d)
+
+	// The motor remembers 1|2 = 3, but outputs its initial memory of 0, which
+	// terminates right away.
+	if got, want := b.Fire([][]SignalType{{1}, {2}}), [][]SignalType{{}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Want %v, got %v", want, got)
+	}
+	// The 3 from the last call comes out first, then 4|1 = 5 is remembered while
+	// the 0 from before is output as a termination.
+	if got, want := b.Fire([][]SignalType{{4}, {1}}), [][]SignalType{{3}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Want %v, got %v", want, got)
+	}
+	if got, want := b.state[2], SignalType(5); got != want {
+		t.Errorf("Want %v, got %v", want, got)
+	}
+
+	// Without the reset, the 5 would be output.
+	b.Reset()
+	if got, want := b.Fire([][]SignalType{{4}, {1}}), [][]SignalType{{}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Want %v, got %v", want, got)
+	}
+
+	if got, want := d.PrettyPrint(), "0 (V0) = op2 <0> [2]\n1 (V1) = op2 <0> [2]\n2 (M0) = op2 {MEMORY}\n"; got != want {
+		t.Errorf("Want %s, got %s", want, got)
+	}
+}
+
+func TestBrainThreshold(t *testing.T) {
+	d := SimpleTestDNA()
+	b := Flourish(d)
+	motor := d.Neurons[2]
+
+	motor.Threshold = AllInputs
+	if b.isReady(2, motor, []pendingSignal[SignalType]{{0, 1}, {0, 2}}) {
+		t.Errorf("Should wait for the second slot")
+	}
+	if !b.isReady(2, motor, []pendingSignal[SignalType]{{0, 1}, {1, 2}}) {
+		t.Errorf("Should be ready with both slots")
+	}
+
+	motor.Threshold = 3
+	if b.isReady(2, motor, []pendingSignal[SignalType]{{0, 1}, {1, 2}}) {
+		t.Errorf("Should wait for a third signal")
+	}
+	motor.SetSeed(5)
+	if !b.isReady(2, motor, []pendingSignal[SignalType]{{0, 1}, {1, 2}}) {
+		t.Errorf("The seed should count towards the threshold")
+	}
+
+	motor.Threshold = 1
+	motor.RemoveSeed()
+	b.addPendingSignal(2, 0, 7)
+	b.stepFunction()
+	if got, want := b.outputSignals[0].signalString, []SignalType{7}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Want %v, got %v", want, got)
+	}
+}
+
+func TestBrainRefractory(t *testing.T) {
+	d := SimpleTestDNA()
+	d.Neurons[2].Refractory = 2
+	b := Flourish(d)
+
+	b.addPendingSignal(2, 0, 1)
+	b.addPendingSignal(2, 1, 2)
+	b.stepFunction()
+	if _, ok := b.pendingSignals[2]; ok {
+		t.Fatalf("Expected the motor neuron to fire")
+	}
+
+	// Rests for two steps while the signals wait.
+	b.addPendingSignal(2, 0, 3)
+	b.addPendingSignal(2, 1, 4)
+	for i := 0; i < 2; i++ {
+		b.stepFunction()
+		if got, want := len(b.pendingSignals[2]), 2; got != want {
+			t.Fatalf("Step %d: want %v pending, got %v", i, want, got)
+		}
+	}
+
+	b.stepFunction()
+	if _, ok := b.pendingSignals[2]; ok {
+		t.Errorf("Expected the motor neuron to fire after resting")
+	}
+	if got, want := b.outputSignals[0].signalString, []SignalType{3, 7}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Want %v, got %v", want, got)
+	}
+}
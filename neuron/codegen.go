@@ -0,0 +1,434 @@
+package neuron
+
+import (
+	"fmt"
+	"go/format"
+	"go/token"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+// CodegenConfig controls the Go source generated from a DNA.
+type CodegenConfig struct {
+	// Package is the name of the generated package.
+	Package string
+	// StepBudget is the most steps Eval takes, or the DefaultStepBudget when
+	// it's 0.
+	StepBudget int
+
+	// TestCases is the number of random inputs in the generated test, which
+	// are drawn using the Seed.
+	TestCases int
+	Seed      int64
+}
+
+func (c CodegenConfig) stepBudget() int {
+	if c.StepBudget <= 0 {
+		return DefaultStepBudget
+	}
+	return c.StepBudget
+}
+
+// combineSource is the Go source of the Combine function of each built in
+// operator, for 8 bit signals. Operators that aren't here are written out as
+// a table of every pair of inputs instead.
+var combineSource = map[OperatorType]string{
+	AND:      "return a & b",
+	NAND:     "return a & b",
+	OR:       "return a | b",
+	NOR:      "return a | b",
+	XOR:      "return a ^ b",
+	IFF:      "return a ^ b",
+	ADD:      "return a + b",
+	MULTIPLY: "return a * b",
+	GCF:      "for b != 0 {\na, b = b, a%b\n}\nreturn a",
+	MAX:      "if a > b {\nreturn a\n}\nreturn b",
+	MIN:      "if a < b {\nreturn a\n}\nreturn b",
+	TRUTH:    "return 0xff",
+	FALSIFY:  "return 0",
+	SUBTRACT: "return a - b",
+	DIVIDE:   "if b == 0 {\nreturn 0xff\n}\nreturn a / b",
+	MOD:      "if b == 0 {\nreturn a\n}\nreturn a % b",
+	// Go shifts of 64 bits or more give 0, just like Word shifts.
+	SHIFT_LEFT:  "return a << b",
+	SHIFT_RIGHT: "return a >> b",
+	COMPARE:     "if a > b {\nreturn 0xff\n}\nreturn 0",
+	SAT_ADD:     "if a+b > 0xff {\nreturn 0xff\n}\nreturn a + b",
+	SAT_MUL:     "if a*b > 0xff {\nreturn 0xff\n}\nreturn a * b",
+	AVERAGE:     "return (a + b) / 2",
+	ABS_DIFF:    "if a > b {\nreturn a - b\n}\nreturn b - a",
+	MUL_HIGH:    "return (a * b) >> 8",
+}
+
+// GoSource generates a self-contained Go file with a function
+//
+//	func Eval(inputs [][]uint8) [][]uint8
+//
+// that gives the same outputs as firing a newly flourished Brain of the DNA
+// once, so an evolved brain can be shipped without this package. Only DNA
+// with 8 bit signals can be generated.
+func (d *DNA[S]) GoSource(c CodegenConfig) ([]byte, error) {
+	if err := d.checkCodegen(c); err != nil {
+		return nil, err
+	}
+	p := Compile(d)
+
+	var sb strings.Builder
+	sb.WriteString("// Code generated by neuron.GoSource. DO NOT EDIT.\n//\n")
+	for _, line := range strings.Split(strings.TrimSpace(d.PrettyPrint()), "\n") {
+		sb.WriteString(fmt.Sprintf("//\t%s\n", line))
+	}
+	sb.WriteString(fmt.Sprintf("\npackage %s\n\n", c.Package))
+
+	sb.WriteString(fmt.Sprintf("// stepBudget is the most steps Eval takes.\nconst stepBudget = %d\n\n", c.stepBudget()))
+	sb.WriteString(fmt.Sprintf("const (\nnumNeurons = %d\nnumMotors = %d\n", len(p.ids), p.numMotors))
+	sb.WriteString("// allInputs is the threshold of neurons that wait on every input slot.\nallInputs = -1\n)\n\n")
+
+	threshold := make([]int, len(p.ids))
+	refractory := make([]int, len(p.ids))
+	hasSeed := make([]bool, len(p.ids))
+	for i, neuron := range p.neurons {
+		threshold[i] = neuron.Threshold
+		refractory[i] = neuron.Refractory
+		hasSeed[i] = neuron.HasSeed
+	}
+	sb.WriteString("// The neurons are numbered in ID order, and the arrays below work the same\n")
+	sb.WriteString("// as a neuron.Plan.\nvar (\n")
+	writeGoSlice(&sb, "sense", "int", p.sense)
+	writeGoSlice(&sb, "motor", "int", p.motor)
+	writeGoSlice(&sb, "slotStart", "int", p.slotStart)
+	writeGoSlice(&sb, "slotNeuron", "int", p.slotNeuron)
+	writeGoSlice(&sb, "outStart", "int", p.outStart)
+	writeGoSlice(&sb, "outSlot", "int", p.outSlot)
+	writeGoSlice(&sb, "threshold", "int", threshold)
+	writeGoSlice(&sb, "refractory", "int", refractory)
+	writeGoSlice(&sb, "hasSeed", "bool", hasSeed)
+	sb.WriteString(")\n\n")
+	sb.WriteString(goEvalSource)
+
+	d.writeGoFire(&sb, p)
+
+	usedOps := make(map[OperatorType]bool)
+	for _, neuron := range p.neurons {
+		if neuron.Kind != TABLE {
+			usedOps[neuron.Op] = true
+		}
+	}
+	ops := make([]OperatorType, 0, len(usedOps))
+	for op := range usedOps {
+		ops = append(ops, op)
+	}
+	sort.Slice(ops, func(i, j int) bool { return ops[i] < ops[j] })
+	for _, op := range ops {
+		writeGoOp(&sb, op)
+	}
+
+	for i, neuron := range p.neurons {
+		if neuron.Kind == TABLE && len(neuron.Table) == TableSize {
+			sb.WriteString(fmt.Sprintf("\n// table%d is the lookup table of neuron %d.\n", i, p.ids[i]))
+			writeGoBytes(&sb, fmt.Sprintf("table%d = [%d]uint8", i, TableSize), neuron.Table)
+		}
+	}
+	return format.Source([]byte(sb.String()))
+}
+
+// GoTestSource generates a test for the GoSource, which checks Eval against
+// the outputs of a Brain for random inputs.
+func (d *DNA[S]) GoTestSource(c CodegenConfig) ([]byte, error) {
+	if err := d.checkCodegen(c); err != nil {
+		return nil, err
+	}
+
+	rnd := rand.New(rand.NewSource(c.Seed))
+	var sb strings.Builder
+	sb.WriteString("// Code generated by neuron.GoTestSource. DO NOT EDIT.\n\n")
+	sb.WriteString(fmt.Sprintf("package %s\n\n", c.Package))
+	sb.WriteString("import (\n\"reflect\"\n\"testing\"\n)\n\n")
+	sb.WriteString("func TestEval(t *testing.T) {\nfor _, test := range []struct {\ninputs [][]uint8\nwant [][]uint8\n}{\n")
+	for i := 0; i < c.TestCases; i++ {
+		inputs := make([][]S, d.Source.NeuronIDs[SENSE].Length())
+		for j := range inputs {
+			inputs[j] = make([]S, rnd.Intn(5))
+			for k := range inputs[j] {
+				inputs[j][k] = S(rnd.Intn(TableSize))
+			}
+		}
+		brain := Flourish(d)
+		brain.SetStepBudget(c.stepBudget())
+		want := brain.Fire(inputs)
+		sb.WriteString(fmt.Sprintf("{%s, %s},\n", goSignalStrings(inputs), goSignalStrings(want)))
+	}
+	sb.WriteString("} {\nif got := Eval(test.inputs); !reflect.DeepEqual(got, test.want) {\n")
+	sb.WriteString("t.Errorf(\"Eval(%v): got %v, want %v\", test.inputs, got, test.want)\n}\n}\n}\n")
+	return format.Source([]byte(sb.String()))
+}
+
+func (d *DNA[S]) checkCodegen(c CodegenConfig) error {
+	if w := WidthOf[S](); w != WidthOf[uint8]() {
+		return fmt.Errorf("can't generate code for %d bit signals, only 8", w.Bits)
+	}
+	if !token.IsIdentifier(c.Package) {
+		return fmt.Errorf("invalid package name %q", c.Package)
+	}
+	return nil
+}
+
+// goEvalSource is the part of the generated source that doesn't depend on
+// the DNA. It fires the same way as a CompiledBrain.
+const goEvalSource = `// Eval fires the brain once, the same as a newly grown neuron.Brain, and
+// returns the output of each MOTOR neuron. Outputs that aren't terminated
+// within the step budget are empty.
+func Eval(inputs [][]uint8) [][]uint8 {
+	pending := make([][]uint8, len(slotNeuron))
+	numPending := make([]int, numNeurons)
+	numFilled := make([]int, numNeurons)
+	state := make([]uint8, numNeurons)
+	restUntil := make([]int, numNeurons)
+	outputs := make([][]uint8, numMotors)
+	terminated := make([]bool, numMotors)
+
+	// deliver adds the signal behind any others waiting in the same slot.
+	deliver := func(slot int, sig uint8) {
+		i := slotNeuron[slot]
+		if len(pending[slot]) == 0 {
+			numFilled[i]++
+		}
+		pending[slot] = append(pending[slot], sig)
+		numPending[i]++
+	}
+	type delivery struct {
+		slot int
+		sig  uint8
+	}
+	next := make([]delivery, 0, len(outSlot))
+	in := make([]uint8, 0, len(slotNeuron)+1)
+
+	for step := 0; step < stepBudget; step++ {
+		// Each input is followed by a null to terminate it.
+		for visionIndex, input := range inputs {
+			if visionIndex >= len(sense) || step > len(input) {
+				continue
+			}
+			var sig uint8
+			if step < len(input) {
+				sig = input[step]
+			}
+			deliver(slotStart[sense[visionIndex]], sig)
+		}
+
+		next = next[:0]
+		for i := 0; i < numNeurons; i++ {
+			if numPending[i] == 0 || step < restUntil[i] {
+				continue
+			}
+			if threshold[i] == allInputs {
+				if numFilled[i] < slotStart[i+1]-slotStart[i] {
+					continue
+				}
+			} else {
+				numInputs := numPending[i]
+				if hasSeed[i] {
+					numInputs++
+				}
+				if numInputs < threshold[i] {
+					continue
+				}
+			}
+			restUntil[i] = step + 1 + refractory[i]
+
+			in = in[:0]
+			for slot := slotStart[i]; slot < slotStart[i+1]; slot++ {
+				in = append(in, pending[slot]...)
+				pending[slot] = pending[slot][:0]
+			}
+			numPending[i] = 0
+			numFilled[i] = 0
+
+			var output uint8
+			output, state[i] = fire(i, in, state[i])
+			if motorIndex := motor[i]; motorIndex >= 0 && !terminated[motorIndex] {
+				if output == 0 {
+					terminated[motorIndex] = true
+				} else {
+					outputs[motorIndex] = append(outputs[motorIndex], output)
+				}
+			}
+			for out := outStart[i]; out < outStart[i+1]; out++ {
+				next = append(next, delivery{slot: outSlot[out], sig: output})
+			}
+		}
+		for _, d := range next {
+			deliver(d.slot, d.sig)
+		}
+
+		allTerminated := true
+		for _, t := range terminated {
+			if !t {
+				allTerminated = false
+			}
+		}
+		if allTerminated {
+			break
+		}
+	}
+
+	for motorIndex := range outputs {
+		if !terminated[motorIndex] || outputs[motorIndex] == nil {
+			outputs[motorIndex] = []uint8{}
+		}
+	}
+	return outputs
+}
+
+// lookup finds the table entry for the inputs, the same as a TABLE neuron.
+func lookup(table *[256]uint8, in []uint8) uint8 {
+	if len(in) == 0 {
+		return 0
+	}
+	if len(in) == 1 {
+		return table[in[0]]
+	}
+	hash := uint64(14695981039346656037)
+	for _, sig := range in {
+		hash ^= uint64(sig)
+		hash *= 1099511628211
+	}
+	return table[hash%256]
+}
+
+`
+
+// writeGoFire writes the fire function, which has a case for every neuron
+// that runs its op or table along with its seed and kind.
+func (d *DNA[S]) writeGoFire(sb *strings.Builder, p *Plan[S]) {
+	sb.WriteString("// fire runs neuron i on its inputs, and returns its output and next state.\n")
+	sb.WriteString("func fire(i int, in []uint8, state uint8) (uint8, uint8) {\nswitch i {\n")
+	for i, neuron := range p.neurons {
+		sb.WriteString(fmt.Sprintf("case %d: // %d\n", i, p.ids[i]))
+		if neuron.HasSeed {
+			// Same as Neuron.Fire, the seed is kept over the other inputs.
+			if max := neuron.Op.Operator().MaxInputs; neuron.Kind != TABLE && max > 0 {
+				sb.WriteString(fmt.Sprintf("if len(in) >= %d {\nin = in[:%d:%d]\n}\n", max, max-1, max-1))
+			}
+			sb.WriteString(fmt.Sprintf("in = append(in, %d)\n", neuron.Seed))
+		}
+
+		var result string
+		switch {
+		case neuron.Kind == TABLE && len(neuron.Table) == TableSize:
+			result = fmt.Sprintf("lookup(&table%d, in)", i)
+		case neuron.Kind == TABLE:
+			result = "0"
+		default:
+			result = fmt.Sprintf("%s(in)", goOpName(neuron.Op))
+		}
+
+		switch neuron.Kind {
+		case LATCH:
+			sb.WriteString(fmt.Sprintf("if state == 0 {\nstate = %s\n}\nreturn state, state\n", result))
+		case ACCUMULATOR:
+			sb.WriteString(fmt.Sprintf("state += %s\nreturn state, state\n", result))
+		case COUNTER:
+			sb.WriteString(fmt.Sprintf("if %s != 0 {\nstate++\n}\nreturn state, state\n", result))
+		case MEMORY:
+			sb.WriteString(fmt.Sprintf("return state, %s\n", result))
+		default:
+			sb.WriteString(fmt.Sprintf("return %s, state\n", result))
+		}
+	}
+	sb.WriteString("}\nreturn 0, state\n}\n")
+}
+
+// goOpName is the name of the generated function for the op, which uses the
+// op name when it makes for a valid identifier.
+func goOpName(op OperatorType) string {
+	if name := "op" + op.String(); token.IsIdentifier(name) {
+		return name
+	}
+	return fmt.Sprintf("op%d", op)
+}
+
+// writeGoOp writes a function that operates on the inputs the same way as
+// Operate, along with its combine function.
+func writeGoOp(sb *strings.Builder, op OperatorType) {
+	def := op.Operator()
+	w := WidthOf[uint8]()
+	name := goOpName(op)
+
+	sb.WriteString(fmt.Sprintf("\n// %s operates like %s.\nfunc %s(in []uint8) uint8 {\n", name, def.Name, name))
+	if def.MinInputs > 0 {
+		sb.WriteString(fmt.Sprintf("if len(in) < %d {\nreturn %d\n}\n", def.MinInputs, w.Mask(def.identity(w))))
+	}
+	if def.MaxInputs > 0 {
+		sb.WriteString(fmt.Sprintf("if len(in) > %d {\nin = in[:%d]\n}\n", def.MaxInputs, def.MaxInputs))
+	}
+	if def.Identity != nil {
+		sb.WriteString(fmt.Sprintf("x := uint64(%d)\n", w.Mask(def.Identity(w))))
+	} else {
+		sb.WriteString("var x uint64\nif len(in) > 0 {\nx = uint64(in[0])\nin = in[1:]\n}\n")
+	}
+	sb.WriteString(fmt.Sprintf("for _, sig := range in {\nx = %sCombine(x, uint64(sig)) & 0xff\n}\n", name))
+	if def.Invert {
+		sb.WriteString("return uint8(^x)\n}\n")
+	} else {
+		sb.WriteString("return uint8(x)\n}\n")
+	}
+
+	sb.WriteString(fmt.Sprintf("\nfunc %sCombine(a, b uint64) uint64 {\n", name))
+	if source, ok := combineSource[op]; ok {
+		sb.WriteString(source + "\n}\n")
+		return
+	}
+	sb.WriteString(fmt.Sprintf("return uint64(%sTable[a][b])\n}\n\n", name))
+
+	// Both inputs are already masked, so every pair fits in the table.
+	sb.WriteString(fmt.Sprintf("var %sTable = [256][256]uint8{\n", name))
+	for a := Word(0); a <= w.Max(); a++ {
+		row := make([]uint8, w.Max()+1)
+		for b := range row {
+			row[b] = uint8(w.Mask(def.Combine(a, Word(b), w)))
+		}
+		sb.WriteString("{")
+		for b, x := range row {
+			if b > 0 {
+				sb.WriteString(", ")
+			}
+			sb.WriteString(fmt.Sprint(x))
+		}
+		sb.WriteString("},\n")
+	}
+	sb.WriteString("}\n")
+}
+
+func writeGoSlice[T any](sb *strings.Builder, name, typ string, values []T) {
+	strs := make([]string, len(values))
+	for i, v := range values {
+		strs[i] = fmt.Sprint(v)
+	}
+	sb.WriteString(fmt.Sprintf("%s = []%s{%s}\n", name, typ, strings.Join(strs, ", ")))
+}
+
+func writeGoBytes[S Signal](sb *strings.Builder, decl string, values []S) {
+	sb.WriteString(fmt.Sprintf("var %s{", decl))
+	for i, v := range values {
+		if i%16 == 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(fmt.Sprintf("%d, ", v))
+	}
+	sb.WriteString("\n}\n")
+}
+
+// goSignalStrings writes the signal strings as a Go literal.
+func goSignalStrings[S Signal](strs [][]S) string {
+	parts := make([]string, len(strs))
+	for i, str := range strs {
+		sigs := make([]string, len(str))
+		for j, sig := range str {
+			sigs[j] = fmt.Sprint(sig)
+		}
+		parts[i] = "{" + strings.Join(sigs, ", ") + "}"
+	}
+	return "[][]uint8{" + strings.Join(parts, ", ") + "}"
+}
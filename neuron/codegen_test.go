@@ -0,0 +1,142 @@
+package neuron
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// codegenTestDNA has a MOTOR neuron for every registered op, so the output of
+// each op can be checked, with the kinds and other genes mixed in. Seeds are
+// covered by the SENSE neurons. The extra op it registers is removed when the
+// test is done.
+func codegenTestDNA(t *testing.T) *DNA[SignalType] {
+	// Ops outside of the built in ones are generated as tables.
+	halfSum, err := RegisterOperator(Operator{
+		Name:     "TEST_CODEGEN_HALF_SUM",
+		Identity: ZeroIdentity,
+		Combine: func(a, b Word, w Width) Word {
+			return (a + b) / 2
+		},
+	})
+	if err != nil {
+		t.Fatalf("Got error %v", err)
+	}
+	t.Cleanup(func() { registry.unregister(halfSum) })
+
+	ops := RegisteredOps()
+	c := NewConglomerate()
+	c.AddVisionAndMotor(2, len(ops))
+	d := NewDNA[SignalType](c)
+	// The SENSE neurons pass their inputs along, nulls included.
+	d.AddNeuron(0, OR)
+	d.SetSeed(0, 0)
+	d.AddNeuron(1, XOR)
+	d.SetSeed(1, 0)
+
+	kinds := []NeuronKind{PURE, LATCH, ACCUMULATOR, COUNTER, MEMORY, TABLE}
+	for motorIndex, op := range ops {
+		motorID := c.NeuronIDs[MOTOR].GetID(motorIndex)
+		d.AddNeuron(motorID, op)
+		// Stateful neurons rarely terminate, so most are left PURE.
+		if motorIndex%3 == 0 {
+			d.Neurons[motorID].Kind = kinds[motorIndex/3%len(kinds)]
+		}
+		d.Neurons[motorID].Refractory = motorIndex % 2
+		if motorIndex%5 == 0 {
+			d.Neurons[motorID].Threshold = AllInputs
+		}
+		if d.Neurons[motorID].Kind == TABLE {
+			table := make([]SignalType, TableSize)
+			for i := range table {
+				table[i] = SignalType(i*31 + 5)
+			}
+			d.Neurons[motorID].SetTable(table)
+		}
+	}
+	for synID := range c.Synapses.idMap {
+		d.AddSynapse(synID)
+	}
+	return d
+}
+
+func TestGoSource(t *testing.T) {
+	src, err := SimpleTestDNA().GoSource(CodegenConfig{Package: "adder"})
+	if err != nil {
+		t.Fatalf("Got error %v", err)
+	}
+	for _, want := range []string{"package adder", "func Eval(inputs [][]uint8) [][]uint8", "const stepBudget = 100", "func opOR("} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("Want %q in:\n%s", want, src)
+		}
+	}
+
+	if _, err := SimpleTestDNA().GoSource(CodegenConfig{Package: "not a package"}); err == nil {
+		t.Errorf("Want error for package name, got none")
+	}
+	if _, err := NewDNA[uint16](NewConglomerate()).GoSource(CodegenConfig{Package: "wide"}); err == nil {
+		t.Errorf("Want error for 16 bit signals, got none")
+	}
+}
+
+// TestGoSourceMatchesBrain builds and runs the generated tests, which compare
+// Eval against a Brain on random inputs.
+func TestGoSourceMatchesBrain(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Builds the generated code")
+	}
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("No go tool to build the generated code")
+	}
+
+	seed := time.Now().UnixNano()
+	rnd := rand.New(rand.NewSource(seed))
+	dnas := []*DNA[SignalType]{SimpleTestDNA(), codegenTestDNA(t)}
+	for i := 0; i < 6; i++ {
+		dnas = append(dnas, randomTestDNA(rnd))
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module evaltest\n\ngo 1.18\n"), 0644); err != nil {
+		t.Fatalf("Got error %v", err)
+	}
+	for i, dna := range dnas {
+		config := CodegenConfig{
+			Package:    fmt.Sprintf("brain%d", i),
+			StepBudget: 1 + rnd.Intn(2*DefaultStepBudget),
+			TestCases:  50,
+			Seed:       seed + int64(i),
+		}
+		src, err := dna.GoSource(config)
+		if err != nil {
+			t.Fatalf("Got error %v", err)
+		}
+		testSrc, err := dna.GoTestSource(config)
+		if err != nil {
+			t.Fatalf("Got error %v", err)
+		}
+
+		pkgDir := filepath.Join(dir, config.Package)
+		if err := os.Mkdir(pkgDir, 0755); err != nil {
+			t.Fatalf("Got error %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(pkgDir, "eval.go"), src, 0644); err != nil {
+			t.Fatalf("Got error %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(pkgDir, "eval_test.go"), testSrc, 0644); err != nil {
+			t.Fatalf("Got error %v", err)
+		}
+	}
+
+	cmd := exec.Command(goTool, "test", "./...")
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Errorf("Seed %d: generated tests failed: %v\n%s", seed, err, out)
+	}
+}
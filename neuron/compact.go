@@ -0,0 +1,86 @@
+package neuron
+
+// CompactionReport says how much of a conglomerate was reclaimed by Compact,
+// and how much is left.
+type CompactionReport struct {
+	RemovedNeurons  int
+	RemovedSynapses int
+	Neurons         int
+	Synapses        int
+}
+
+// Compact removes every INTER neuron and synapse that isn't being kept, so
+// the conglomerate doesn't keep growing with structure that no DNA uses. The
+// neurons at either end of a kept synapse are kept too, along with every
+// SENSE and MOTOR neuron and the synapses between them that
+// AddVisionAndMotor starts with. Nothing is renumbered, so the IDs of the
+// neurons and synapses that are left stay the same, and removed IDs are never
+// used again.
+func (c *Conglomerate) Compact(neurons, synapses IDSet) CompactionReport {
+	report := CompactionReport{}
+	for synID, syn := range c.Synapses.idMap {
+		if _, ok := synapses[synID]; ok {
+			continue
+		}
+		if c.GetNeuronType(syn.src) != INTER && c.GetNeuronType(syn.dst) != INTER {
+			continue
+		}
+		c.Synapses.RemoveSynapse(synID)
+		report.RemovedSynapses++
+	}
+
+	used := make(IDSet, len(neurons))
+	for neuronID := range neurons {
+		used[neuronID] = member
+	}
+	for _, syn := range c.Synapses.idMap {
+		used[syn.src] = member
+		used[syn.dst] = member
+	}
+
+	// Rebuilding the INTER neurons keeps their order without shifting the
+	// indices once for every removal.
+	inter := NewIndexedIDs()
+	for index := 0; index < c.NeuronIDs[INTER].Length(); index++ {
+		neuronID := c.NeuronIDs[INTER].GetID(index)
+		if _, ok := used[neuronID]; ok {
+			inter.InsertID(neuronID)
+		} else {
+			report.RemovedNeurons++
+		}
+	}
+	c.NeuronIDs[INTER] = inter
+
+	for _, nType := range NeuronTypes {
+		report.Neurons += c.NeuronIDs[nType].Length()
+	}
+	report.Synapses = len(c.Synapses.idMap)
+	return report
+}
+
+// Compact removes the structure of the conglomerate that isn't used by the
+// current generation, any species representative or the pinned DNA, which
+// also speeds up mutateDNAStructure since it looks through the whole
+// conglomerate.
+func (p *Playground[S]) Compact() CompactionReport {
+	neurons := make(IDSet)
+	synapses := make(IDSet)
+	addDNA := func(dna *DNA[S]) {
+		for neuronID := range dna.Neurons {
+			neurons[neuronID] = member
+		}
+		for synID := range dna.Synpases.idMap {
+			synapses[synID] = member
+		}
+	}
+	for _, dna := range p.codes {
+		addDNA(dna)
+	}
+	for _, species := range p.species {
+		addDNA(species.rep)
+	}
+	for _, dna := range p.pinned {
+		addDNA(dna)
+	}
+	return p.source.Compact(neurons, synapses)
+}
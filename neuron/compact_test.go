@@ -0,0 +1,151 @@
+package neuron
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestConglomerateCompact(t *testing.T) {
+	c := NewConglomerate()
+	c.AddVisionAndMotor(2, 1)
+	kept := c.AddInterNeuron(0)
+	dropped := c.AddInterNeuron(1)
+	loop := c.Synapses.AddNewSynapse(kept, dropped)
+
+	d := NewDNA[SignalType](c)
+	for _, neuronID := range []IDType{0, 1, 2, kept} {
+		d.AddNeuron(neuronID, OR)
+	}
+	for synID, syn := range c.Synapses.idMap {
+		if syn.src != dropped && syn.dst != dropped {
+			d.AddSynapse(synID)
+		}
+	}
+	before := Flourish(d).Fire([][]SignalType{{1, 2}, {3}})
+
+	neurons := make(IDSet)
+	for neuronID := range d.Neurons {
+		neurons[neuronID] = member
+	}
+	synapses := make(IDSet)
+	for synID := range d.Synpases.idMap {
+		synapses[synID] = member
+	}
+	report := c.Compact(neurons, synapses)
+	if got, want := report, (CompactionReport{RemovedNeurons: 1, RemovedSynapses: 3, Neurons: 4, Synapses: 4}); got != want {
+		t.Errorf("Got %+v, want %+v", got, want)
+	}
+	if got, want := c.NeuronIDs[INTER].HasID(dropped), false; got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+	if _, ok := c.Synapses.idMap[loop]; ok {
+		t.Errorf("Want synapse %d removed", loop)
+	}
+	// The DNA keeps working since none of its IDs changed.
+	if got, want := Flourish(d).Fire([][]SignalType{{1, 2}, {3}}), before; !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+
+	// Removed IDs are never handed out again.
+	if got, want := c.AddInterNeuron(0), dropped+1; got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+	if got, want := c.Synapses.AddNewSynapse(kept, 2), loop+3; got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+}
+
+func TestConglomerateCompactKeepsBase(t *testing.T) {
+	c := NewConglomerate()
+	c.AddVisionAndMotor(2, 2)
+	report := c.Compact(make(IDSet), make(IDSet))
+	if got, want := report, (CompactionReport{Neurons: 4, Synapses: 4}); got != want {
+		t.Errorf("Got %+v, want %+v", got, want)
+	}
+}
+
+func TestPlaygroundCompactEvery(t *testing.T) {
+	runner := createTestRunner()
+	runner.play.config.CompactEvery = 1
+	runner.play.InitDNA()
+	for gen := 0; gen < 4; gen++ {
+		runner.runGeneration(gen)
+
+		// Every gene of every DNA is still in the conglomerate.
+		source := runner.play.source
+		for id, dna := range runner.play.codes {
+			for neuronID := range dna.Neurons {
+				if !source.hasNeuron(neuronID) {
+					t.Errorf("Generation %d, DNA %d: neuron %d was removed", gen, id, neuronID)
+				}
+			}
+			for synID, syn := range dna.Synpases.idMap {
+				if got, want := source.Synapses.idMap[synID], syn; got != want {
+					t.Errorf("Generation %d, DNA %d: got synapse %+v, want %+v", gen, id, got, want)
+				}
+			}
+		}
+		if got, want := runner.play.Compact().RemovedNeurons, 0; got != want {
+			t.Errorf("Generation %d: got %v, want %v", gen, got, want)
+		}
+	}
+}
+
+func TestMutateDNAStructureAfterCompact(t *testing.T) {
+	config := createTestPlayConfig()
+	config.Mconf.AddSynapse = 0
+	p := NewPlayground[SignalType](config)
+	p.source.AddVisionAndMotor(2, 1)
+	// Split 0->2 into 0->3->2, then split 0->3 into 0->4->3.
+	p.source.AddInterNeuron(0)
+	p.source.AddInterNeuron(2)
+
+	a := SimpleTestDNA()
+	a.Source = p.source
+	a.AddNeuron(3, OR)
+	a.AddSynapse(3)
+	b := a.DeepCopy()
+	b.AddNeuron(4, OR)
+	b.AddSynapse(4)
+	b.AddSynapse(5)
+	p.codes = map[IDType]*DNA[SignalType]{0: a, 1: b}
+
+	// Nothing uses 0->3 anymore, so it's removed while 4 is kept.
+	p.Compact()
+	if _, ok := p.source.Synapses.idMap[2]; ok {
+		t.Fatalf("Want synapse 2 removed")
+	}
+
+	// Splitting 0->3 with 4 would need to remove 0->3, which is gone.
+	p.mutateDNAStructure(a)
+	if _, ok := a.Synpases.idMap[0]; !ok {
+		t.Errorf("Want synapse 0 kept")
+	}
+	if _, ok := a.Neurons[4]; ok {
+		t.Errorf("Want neuron 4 left out")
+	}
+	if err := a.Validate(); err != nil {
+		t.Errorf("Got error %v", err)
+	}
+}
+
+func TestCompactKeepsHallOfFame(t *testing.T) {
+	config := createTestRunner().config
+	config.HallOfFame = 3
+	config.PConf.CompactEvery = 1
+	runner := NewRunner(config)
+	runner.play.InitDNA()
+	for gen := 0; gen < 6; gen++ {
+		runner.runGeneration(gen)
+	}
+
+	// The hall of fame and the last generation outlive the compactions.
+	for i, member := range append(runner.HallOfFame(), runner.TopMembers(1000)...) {
+		if err := member.DNA.Validate(); err != nil {
+			t.Fatalf("Member %d: got error %v", i, err)
+		}
+		if _, err := NewPredictor(member.DNA, PredictorConfig{}); err != nil {
+			t.Errorf("Member %d: got error %v", i, err)
+		}
+	}
+}
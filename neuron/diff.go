@@ -0,0 +1,191 @@
+package neuron
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DNADiff is everything that changed from one DNA to another. Both DNA share
+// a Conglomerate, so their genes line up by ID the same way as in
+// dnaDistance.
+type DNADiff struct {
+	AddedNeurons   []IDType        `json:"added_neurons,omitempty"`
+	RemovedNeurons []IDType        `json:"removed_neurons,omitempty"`
+	ChangedNeurons []NeuronChanges `json:"changed_neurons,omitempty"`
+
+	AddedSynapses   []IDType `json:"added_synapses,omitempty"`
+	RemovedSynapses []IDType `json:"removed_synapses,omitempty"`
+
+	// names has a readable name for every neuron in the diff, and ends has
+	// the src and dst of every synapse, for the sake of Text.
+	names map[IDType]string
+	ends  map[IDType]Synapse
+}
+
+// NeuronChanges are the genes that changed on a neuron both DNA have.
+type NeuronChanges struct {
+	Neuron IDType       `json:"neuron"`
+	Genes  []GeneChange `json:"genes"`
+}
+
+// GeneChange is a single gene of a neuron, written as text so that any gene
+// fits.
+type GeneChange struct {
+	Gene string `json:"gene"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Diff returns the changes that turn this DNA into the other one.
+func (d *DNA[S]) Diff(other *DNA[S]) *DNADiff {
+	diff := &DNADiff{
+		names: make(map[IDType]string),
+		ends:  make(map[IDType]Synapse),
+	}
+
+	for _, neuronID := range sortedNeuronIDs(other) {
+		neuron, ok := d.Neurons[neuronID]
+		if !ok {
+			diff.AddedNeurons = append(diff.AddedNeurons, neuronID)
+			diff.names[neuronID] = describeNeuron(other, neuronID)
+			continue
+		}
+		if genes := geneChanges(neuron, other.Neurons[neuronID]); len(genes) > 0 {
+			diff.ChangedNeurons = append(diff.ChangedNeurons, NeuronChanges{Neuron: neuronID, Genes: genes})
+			diff.names[neuronID] = describeNeuron(other, neuronID)
+		}
+	}
+	for _, neuronID := range sortedNeuronIDs(d) {
+		if _, ok := other.Neurons[neuronID]; !ok {
+			diff.RemovedNeurons = append(diff.RemovedNeurons, neuronID)
+			diff.names[neuronID] = describeNeuron(d, neuronID)
+		}
+	}
+
+	shared := make(IDSet)
+	for _, synID := range sharedSynapses(d, other) {
+		shared[synID] = member
+	}
+	for _, synID := range sortedSynapseIDs(other.Synpases) {
+		if _, ok := shared[synID]; !ok {
+			diff.AddedSynapses = append(diff.AddedSynapses, synID)
+			diff.ends[synID] = other.Synpases.idMap[synID]
+		}
+	}
+	for _, synID := range sortedSynapseIDs(d.Synpases) {
+		if _, ok := shared[synID]; !ok {
+			diff.RemovedSynapses = append(diff.RemovedSynapses, synID)
+			diff.ends[synID] = d.Synpases.idMap[synID]
+		}
+	}
+	return diff
+}
+
+// IsEmpty returns true if both DNA are the same.
+func (diff *DNADiff) IsEmpty() bool {
+	return len(diff.AddedNeurons) == 0 && len(diff.RemovedNeurons) == 0 && len(diff.ChangedNeurons) == 0 &&
+		len(diff.AddedSynapses) == 0 && len(diff.RemovedSynapses) == 0
+}
+
+// Text returns the diff with a line for each change. Lines start with + for
+// added neurons and synapses, - for removed ones and ~ for changed neurons,
+// like "~ neuron 2 (M0): op OR -> AND, seed none -> 5".
+func (diff *DNADiff) Text() string {
+	if diff.IsEmpty() {
+		return "no changes\n"
+	}
+
+	var sb strings.Builder
+	for _, neuronID := range diff.AddedNeurons {
+		sb.WriteString(fmt.Sprintf("+ neuron %s\n", diff.names[neuronID]))
+	}
+	for _, neuronID := range diff.RemovedNeurons {
+		sb.WriteString(fmt.Sprintf("- neuron %s\n", diff.names[neuronID]))
+	}
+	for _, change := range diff.ChangedNeurons {
+		genes := make([]string, len(change.Genes))
+		for i, gene := range change.Genes {
+			genes[i] = fmt.Sprintf("%s %s -> %s", gene.Gene, gene.From, gene.To)
+		}
+		name := strings.SplitN(diff.names[change.Neuron], " = ", 2)[0]
+		sb.WriteString(fmt.Sprintf("~ neuron %s: %s\n", name, strings.Join(genes, ", ")))
+	}
+	for _, synID := range diff.AddedSynapses {
+		sb.WriteString(fmt.Sprintf("+ synapse %d (%d -> %d)\n", synID, diff.ends[synID].src, diff.ends[synID].dst))
+	}
+	for _, synID := range diff.RemovedSynapses {
+		sb.WriteString(fmt.Sprintf("- synapse %d (%d -> %d)\n", synID, diff.ends[synID].src, diff.ends[synID].dst))
+	}
+	return sb.String()
+}
+
+// describeNeuron writes the neuron like "5 (I2) = XOR". The name is left out
+// if the neuron has been compacted out of the conglomerate.
+func describeNeuron[S Signal](d *DNA[S], neuronID IDType) string {
+	desc := fmt.Sprintf("%d", neuronID)
+	for _, nType := range NeuronTypes {
+		if d.Source.NeuronIDs[nType].HasID(neuronID) {
+			desc += fmt.Sprintf(" (%s)", neuronName(nType, d.Source.NeuronIDs[nType].GetIndex(neuronID)))
+		}
+	}
+
+	neuron := d.Neurons[neuronID]
+	if neuron.Kind == TABLE {
+		return desc + " = TABLE"
+	}
+	return desc + " = " + neuron.Op.String()
+}
+
+// geneChanges lists every gene that's different between the two neurons.
+func geneChanges[S Signal](a, b *Neuron[S]) []GeneChange {
+	genes := make([]GeneChange, 0)
+	if a.Op != b.Op {
+		genes = append(genes, GeneChange{Gene: "op", From: a.Op.String(), To: b.Op.String()})
+	}
+	if a.Kind != b.Kind {
+		genes = append(genes, GeneChange{Gene: "kind", From: a.Kind.String(), To: b.Kind.String()})
+	}
+	if a.HasSeed != b.HasSeed || (a.HasSeed && a.Seed != b.Seed) {
+		genes = append(genes, GeneChange{Gene: "seed", From: seedText(a), To: seedText(b)})
+	}
+	if a.Threshold != b.Threshold {
+		genes = append(genes, GeneChange{Gene: "threshold", From: thresholdText(a.Threshold), To: thresholdText(b.Threshold)})
+	}
+	if a.Refractory != b.Refractory {
+		genes = append(genes, GeneChange{Gene: "refractory", From: fmt.Sprint(a.Refractory), To: fmt.Sprint(b.Refractory)})
+	}
+
+	// Tables only matter to TABLE neurons, and a change of kind is already
+	// reported above.
+	if a.Kind != TABLE || b.Kind != TABLE {
+		return genes
+	}
+	if len(a.Table) != len(b.Table) {
+		genes = append(genes, GeneChange{Gene: "table", From: fmt.Sprintf("%d entries", len(a.Table)), To: fmt.Sprintf("%d entries", len(b.Table))})
+	} else {
+		changed := 0
+		for i := range a.Table {
+			if a.Table[i] != b.Table[i] {
+				changed++
+			}
+		}
+		if changed > 0 {
+			genes = append(genes, GeneChange{Gene: "table", From: fmt.Sprintf("%d entries", len(a.Table)), To: fmt.Sprintf("%d changed", changed)})
+		}
+	}
+	return genes
+}
+
+func seedText[S Signal](n *Neuron[S]) string {
+	if !n.HasSeed {
+		return "none"
+	}
+	return fmt.Sprint(n.Seed)
+}
+
+func thresholdText(threshold int) string {
+	if threshold == AllInputs {
+		return "all"
+	}
+	return fmt.Sprint(threshold)
+}
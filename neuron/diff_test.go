@@ -0,0 +1,93 @@
+package neuron
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestDNADiff(t *testing.T) {
+	parent := SimpleTestDNA()
+	child := parent.DeepCopy()
+	if got, want := parent.Diff(child).Text(), "no changes\n"; got != want {
+		t.Errorf("Got %q, want %q", got, want)
+	}
+
+	// Split synapse 0 with a new neuron, which adds synapses 2 and 3.
+	interID := child.Source.AddInterNeuron(0)
+	child.AddNeuron(interID, XOR)
+	child.AddSynapse(2)
+	child.AddSynapse(3)
+	child.RemoveSynapse(0)
+	child.Neurons[2].Op = AND
+	child.SetSeed(2, 5)
+	child.Neurons[2].Threshold = AllInputs
+
+	diff := parent.Diff(child)
+	if got, want := diff.AddedNeurons, []IDType{interID}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+	if got, want := len(diff.RemovedNeurons), 0; got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+	if got, want := diff.ChangedNeurons, []NeuronChanges{{Neuron: 2, Genes: []GeneChange{
+		{Gene: "op", From: "OR", To: "AND"},
+		{Gene: "seed", From: "none", To: "5"},
+		{Gene: "threshold", From: "2", To: "all"},
+	}}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+	if got, want := diff.AddedSynapses, []IDType{2, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+	if got, want := diff.RemovedSynapses, []IDType{0}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+
+	want := "+ neuron 3 (I0) = XOR\n" +
+		"~ neuron 2 (M0): op OR -> AND, seed none -> 5, threshold 2 -> all\n" +
+		"+ synapse 2 (0 -> 3)\n" +
+		"+ synapse 3 (3 -> 2)\n" +
+		"- synapse 0 (0 -> 2)\n"
+	if got := diff.Text(); got != want {
+		t.Errorf("Got %q, want %q", got, want)
+	}
+
+	// Going back the other way removes what was added.
+	back := child.Diff(parent)
+	if got, want := back.RemovedNeurons, []IDType{interID}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+	if got, want := back.AddedSynapses, []IDType{0}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+
+	out, err := json.Marshal(diff)
+	if err != nil {
+		t.Fatalf("Got error %v", err)
+	}
+	loaded := &DNADiff{}
+	if err := json.Unmarshal(out, loaded); err != nil {
+		t.Fatalf("Got error %v", err)
+	}
+	if got, want := loaded.ChangedNeurons, diff.ChangedNeurons; !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+}
+
+func TestDNADiffIgnoresStaleTables(t *testing.T) {
+	parent := SimpleTestDNA()
+	child := parent.DeepCopy()
+	child.Neurons[2].Table = make([]SignalType, TableSize)
+	if got, want := parent.Diff(child).Text(), "no changes\n"; got != want {
+		t.Errorf("Got %q, want %q", got, want)
+	}
+
+	// Becoming a TABLE reports the kind, but not the table it came with.
+	child.Neurons[2].Kind = TABLE
+	if got, want := parent.Diff(child).ChangedNeurons, []NeuronChanges{{Neuron: 2, Genes: []GeneChange{
+		{Gene: "kind", From: "PURE", To: "TABLE"},
+	}}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+}
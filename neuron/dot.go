@@ -0,0 +1,391 @@
+package neuron
+
+import (
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+)
+
+// DOT renders the DNA as a Graphviz digraph. SENSE neurons are ranked at the
+// top and MOTOR neurons at the bottom, and recurrent synapses are dashed so
+// they don't pull the layout out of shape.
+func (d *DNA[S]) DOT() string {
+	var sb strings.Builder
+	sb.WriteString("digraph dna {\n")
+	sb.WriteString("\tnode [shape=box, fontname=\"Helvetica\"];\n")
+	d.writeDOTNeurons(&sb, func(id IDType) bool { return true })
+
+	recurrent := d.recurrentSynapses()
+	for _, synID := range sortedSynapseIDs(d.Synpases) {
+		syn := d.Synpases.idMap[synID]
+		attrs := ""
+		if _, ok := recurrent[synID]; ok {
+			attrs = " [style=dashed, constraint=false]"
+		}
+		sb.WriteString(fmt.Sprintf("\tn%d -> n%d%s;\n", syn.src, syn.dst, attrs))
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// ConglomerateDOT renders the whole Source of the DNA, with the neurons and
+// synapses of this DNA highlighted and the rest greyed out.
+func (d *DNA[S]) ConglomerateDOT() string {
+	var sb strings.Builder
+	sb.WriteString("digraph conglomerate {\n")
+	sb.WriteString("\tnode [shape=box, fontname=\"Helvetica\"];\n")
+	d.writeDOTNeurons(&sb, func(id IDType) bool {
+		_, ok := d.Neurons[id]
+		return ok
+	})
+
+	recurrent := d.recurrentSynapses()
+	for _, synID := range sortedSynapseIDs(d.Source.Synapses) {
+		syn := d.Source.Synapses.idMap[synID]
+		attrs := make([]string, 0)
+		if _, ok := d.Synpases.idMap[synID]; ok {
+			attrs = append(attrs, "penwidth=2")
+			if _, ok := recurrent[synID]; ok {
+				attrs = append(attrs, "style=dashed", "constraint=false")
+			}
+		} else {
+			attrs = append(attrs, "color=gray80")
+		}
+		sb.WriteString(fmt.Sprintf("\tn%d -> n%d [%s];\n", syn.src, syn.dst, strings.Join(attrs, ", ")))
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// writeDOTNeurons writes every neuron in the Source, where neurons that aren't
+// highlighted are greyed out without their ops.
+func (d *DNA[S]) writeDOTNeurons(sb *strings.Builder, highlight func(id IDType) bool) {
+	for _, nType := range NeuronTypes {
+		ids := make([]string, 0)
+		for index := 0; index < d.Source.NeuronIDs[nType].Length(); index++ {
+			neuronID := d.Source.NeuronIDs[nType].GetID(index)
+			neuron, ok := d.Neurons[neuronID]
+			if !highlight(neuronID) {
+				sb.WriteString(fmt.Sprintf("\tn%d [label=\"%s\", color=gray80, fontcolor=gray60];\n", neuronID, dotEscape(neuronName(nType, index))))
+				ids = append(ids, fmt.Sprintf("n%d", neuronID))
+				continue
+			}
+			if !ok {
+				continue
+			}
+
+			lines := neuronLabel(nType, index, neuron)
+			for i := range lines {
+				lines[i] = dotEscape(lines[i])
+			}
+			label := strings.Join(lines, "\\n")
+			sb.WriteString(fmt.Sprintf("\tn%d [label=\"%s\"%s];\n", neuronID, label, dotShape(nType)))
+			ids = append(ids, fmt.Sprintf("n%d", neuronID))
+		}
+
+		if len(ids) == 0 || nType == INTER {
+			continue
+		}
+		rank := "source"
+		if nType == MOTOR {
+			rank = "sink"
+		}
+		sb.WriteString(fmt.Sprintf("\t{ rank=%s; %s; }\n", rank, strings.Join(ids, "; ")))
+	}
+}
+
+// dotEscaper escapes the characters that would end or break a quoted DOT
+// string, since registered op names can have any characters.
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+func dotEscape(s string) string {
+	return dotEscaper.Replace(s)
+}
+
+func dotShape(nType NeuronType) string {
+	switch nType {
+	case SENSE:
+		return ", shape=invhouse"
+	case MOTOR:
+		return ", shape=house"
+	default:
+		return ""
+	}
+}
+
+// neuronName is the short name PrettyPrint uses for a neuron, like V0 or M1.
+func neuronName(nType NeuronType, index int) string {
+	nTypeChar := "I"
+	if nType == SENSE {
+		nTypeChar = "V"
+	} else if nType == MOTOR {
+		nTypeChar = "M"
+	}
+	return fmt.Sprintf("%s%d", nTypeChar, index)
+}
+
+// neuronLabel is the lines describing a neuron: its name, op and the genes
+// that aren't the default.
+func neuronLabel[S Signal](nType NeuronType, index int, neuron *Neuron[S]) []string {
+	lines := []string{neuronName(nType, index)}
+	if neuron.Kind == TABLE {
+		lines = append(lines, "TABLE")
+	} else {
+		lines = append(lines, neuron.Op.String())
+	}
+	if neuron.HasSeed {
+		lines = append(lines, fmt.Sprintf("<%d>", neuron.Seed))
+	}
+	if neuron.Kind != PURE && neuron.Kind != TABLE {
+		lines = append(lines, neuron.Kind.String())
+	}
+	if neuron.Threshold == AllInputs {
+		lines = append(lines, "t*")
+	} else if neuron.Threshold != DefaultThreshold {
+		lines = append(lines, fmt.Sprintf("t%d", neuron.Threshold))
+	}
+	if neuron.Refractory != 0 {
+		lines = append(lines, fmt.Sprintf("r%d", neuron.Refractory))
+	}
+	return lines
+}
+
+func sortedSynapseIDs(s *SynapseTracker) []IDType {
+	synIDs := make([]IDType, 0, len(s.idMap))
+	for synID := range s.idMap {
+		synIDs = append(synIDs, synID)
+	}
+	sort.Ints(synIDs)
+	return synIDs
+}
+
+func sortedNeuronIDs[S Signal](d *DNA[S]) []IDType {
+	neuronIDs := make([]IDType, 0, len(d.Neurons))
+	for neuronID := range d.Neurons {
+		neuronIDs = append(neuronIDs, neuronID)
+	}
+	sort.Ints(neuronIDs)
+	return neuronIDs
+}
+
+// recurrentSynapses returns the synapses that loop back to a neuron that's
+// still being visited in a depth first search starting at the SENSE neurons.
+// Removing them leaves the DNA without any cycles.
+func (d *DNA[S]) recurrentSynapses() IDSet {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	status := make(map[IDType]int, len(d.Neurons))
+	recurrent := make(IDSet)
+
+	var visit func(neuronID IDType)
+	visit = func(neuronID IDType) {
+		status[neuronID] = visiting
+		synIDs := make([]IDType, 0, len(d.Synpases.srcMap[neuronID]))
+		for synID := range d.Synpases.srcMap[neuronID] {
+			synIDs = append(synIDs, synID)
+		}
+		sort.Ints(synIDs)
+
+		for _, synID := range synIDs {
+			dst := d.Synpases.idMap[synID].dst
+			switch status[dst] {
+			case visiting:
+				recurrent[synID] = member
+			case unvisited:
+				visit(dst)
+			}
+		}
+		status[neuronID] = visited
+	}
+
+	// Start from the SENSE neurons so that the forward direction follows the
+	// flow of signals, then pick up anything they don't reach.
+	for index := 0; index < d.Source.NeuronIDs[SENSE].Length(); index++ {
+		if neuronID := d.Source.NeuronIDs[SENSE].GetID(index); status[neuronID] == unvisited {
+			visit(neuronID)
+		}
+	}
+	for _, neuronID := range sortedNeuronIDs(d) {
+		if status[neuronID] == unvisited {
+			visit(neuronID)
+		}
+	}
+	return recurrent
+}
+
+// Sizes of the SVG layout, in pixels.
+const (
+	svgNodeWidth  = 90
+	svgLineHeight = 14
+	svgColumnGap  = 70
+	svgRowGap     = 24
+	svgMargin     = 20
+)
+
+type svgNode struct {
+	x, y, height int
+	lines        []string
+	nType        NeuronType
+}
+
+// SVG renders the DNA as an SVG image without needing Graphviz. Neurons are
+// laid out in columns from left to right by their longest path from a SENSE
+// neuron, with SENSE neurons in the first column and MOTOR neurons in the
+// last. Recurrent synapses are drawn as dashed curves.
+func (d *DNA[S]) SVG() string {
+	recurrent := d.recurrentSynapses()
+	layers := d.svgLayers(recurrent)
+
+	numLayers := 0
+	for _, layer := range layers {
+		if layer+1 > numLayers {
+			numLayers = layer + 1
+		}
+	}
+
+	// Stack the neurons in each column in the same order as PrettyPrint.
+	nodes := make(map[IDType]*svgNode, len(d.Neurons))
+	columnHeights := make([]int, numLayers)
+	for _, nType := range NeuronTypes {
+		for index := 0; index < d.Source.NeuronIDs[nType].Length(); index++ {
+			neuronID := d.Source.NeuronIDs[nType].GetID(index)
+			neuron, ok := d.Neurons[neuronID]
+			if !ok {
+				continue
+			}
+			lines := neuronLabel(nType, index, neuron)
+			layer := layers[neuronID]
+			node := &svgNode{
+				x:      svgMargin + layer*(svgNodeWidth+svgColumnGap),
+				y:      svgMargin + columnHeights[layer],
+				height: (len(lines) + 1) * svgLineHeight,
+				lines:  lines,
+				nType:  nType,
+			}
+			columnHeights[layer] += node.height + svgRowGap
+			nodes[neuronID] = node
+		}
+	}
+
+	maxHeight := 0
+	for _, height := range columnHeights {
+		if height > maxHeight {
+			maxHeight = height
+		}
+	}
+	width := 2*svgMargin + numLayers*svgNodeWidth + (numLayers-1)*svgColumnGap
+	if numLayers == 0 {
+		width = 2 * svgMargin
+	}
+	height := 2*svgMargin + maxHeight
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" font-family=\"Helvetica\" font-size=\"11\">\n", width, height))
+	sb.WriteString("<defs><marker id=\"arrow\" viewBox=\"0 0 10 10\" refX=\"10\" refY=\"5\" markerWidth=\"6\" markerHeight=\"6\" orient=\"auto\"><path d=\"M0,0 L10,5 L0,10 z\"/></marker></defs>\n")
+
+	for _, synID := range sortedSynapseIDs(d.Synpases) {
+		syn := d.Synpases.idMap[synID]
+		src, dst := nodes[syn.src], nodes[syn.dst]
+		if src == nil || dst == nil {
+			continue
+		}
+		if _, ok := recurrent[synID]; ok {
+			// Loop back around underneath both neurons.
+			bottom := src.y + src.height
+			if dstBottom := dst.y + dst.height; dstBottom > bottom {
+				bottom = dstBottom
+			}
+			x1, y1 := src.x+svgNodeWidth/2, src.y+src.height
+			x2, y2 := dst.x+svgNodeWidth/2, dst.y+dst.height
+			sb.WriteString(fmt.Sprintf("<path d=\"M%d,%d C%d,%d %d,%d %d,%d\" fill=\"none\" stroke=\"black\" stroke-dasharray=\"4,3\" marker-end=\"url(#arrow)\"/>\n",
+				x1, y1, x1, bottom+svgRowGap, x2, bottom+svgRowGap, x2, y2))
+			continue
+		}
+		x1, y1 := src.x+svgNodeWidth, src.y+src.height/2
+		x2, y2 := dst.x, dst.y+dst.height/2
+		sb.WriteString(fmt.Sprintf("<line x1=\"%d\" y1=\"%d\" x2=\"%d\" y2=\"%d\" stroke=\"black\" marker-end=\"url(#arrow)\"/>\n", x1, y1, x2, y2))
+	}
+
+	for _, neuronID := range sortedNeuronIDs(d) {
+		node := nodes[neuronID]
+		fill := "white"
+		if node.nType == SENSE {
+			fill = "#dbe9f6"
+		} else if node.nType == MOTOR {
+			fill = "#f6e3db"
+		}
+		sb.WriteString(fmt.Sprintf("<g id=\"n%d\">\n", neuronID))
+		sb.WriteString(fmt.Sprintf("<rect x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" rx=\"4\" fill=\"%s\" stroke=\"black\"/>\n", node.x, node.y, svgNodeWidth, node.height, fill))
+		for i, line := range node.lines {
+			sb.WriteString(fmt.Sprintf("<text x=\"%d\" y=\"%d\" text-anchor=\"middle\">%s</text>\n", node.x+svgNodeWidth/2, node.y+(i+1)*svgLineHeight, html.EscapeString(line)))
+		}
+		sb.WriteString("</g>\n")
+	}
+	sb.WriteString("</svg>\n")
+	return sb.String()
+}
+
+// svgLayers finds the column of each neuron, which is its longest path from
+// a SENSE neuron once the recurrent synapses are ignored. MOTOR neurons all go
+// in the column after everything else.
+func (d *DNA[S]) svgLayers(recurrent IDSet) map[IDType]int {
+	inDegree := make(map[IDType]int, len(d.Neurons))
+	for synID, syn := range d.Synpases.idMap {
+		if _, ok := recurrent[synID]; !ok {
+			inDegree[syn.dst]++
+		}
+	}
+
+	// Kahn's algorithm, going through ready neurons in ID order.
+	layers := make(map[IDType]int, len(d.Neurons))
+	queue := make([]IDType, 0)
+	for _, neuronID := range sortedNeuronIDs(d) {
+		if inDegree[neuronID] == 0 {
+			queue = append(queue, neuronID)
+		}
+	}
+	for len(queue) > 0 {
+		neuronID := queue[0]
+		queue = queue[1:]
+		synIDs := make([]IDType, 0, len(d.Synpases.srcMap[neuronID]))
+		for synID := range d.Synpases.srcMap[neuronID] {
+			synIDs = append(synIDs, synID)
+		}
+		sort.Ints(synIDs)
+
+		for _, synID := range synIDs {
+			syn := d.Synpases.idMap[synID]
+			if _, ok := recurrent[synID]; ok {
+				continue
+			}
+			if layers[neuronID]+1 > layers[syn.dst] {
+				layers[syn.dst] = layers[neuronID] + 1
+			}
+			inDegree[syn.dst]--
+			if inDegree[syn.dst] == 0 {
+				queue = append(queue, syn.dst)
+			}
+		}
+	}
+
+	motorLayer := 1
+	for neuronID, layer := range layers {
+		if d.Source.NeuronIDs[SENSE].HasID(neuronID) {
+			layers[neuronID] = 0
+		} else if !d.Source.NeuronIDs[MOTOR].HasID(neuronID) && layer+1 > motorLayer {
+			motorLayer = layer + 1
+		}
+	}
+	for neuronID := range d.Neurons {
+		if d.Source.NeuronIDs[MOTOR].HasID(neuronID) {
+			layers[neuronID] = motorLayer
+		} else if _, ok := layers[neuronID]; !ok {
+			layers[neuronID] = 0
+		}
+	}
+	return layers
+}
@@ -0,0 +1,128 @@
+package neuron
+
+import (
+	"encoding/xml"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// recurrentTestDNA has a loop between the two inter neurons.
+func recurrentTestDNA() *DNA[SignalType] {
+	d := SimpleTestDNA()
+	first := d.Source.AddInterNeuron(0)
+	second := d.Source.AddInterNeuron(d.Source.Synapses.nextID - 1)
+	back := d.Source.Synapses.AddNewSynapse(second, first)
+
+	d.AddNeuron(first, ADD)
+	d.AddNeuron(second, SUBTRACT)
+	d.SetSeed(second, 12)
+	for synID, syn := range d.Source.Synapses.idMap {
+		if syn.src == first || syn.dst == first || syn.src == second || syn.dst == second {
+			d.AddSynapse(synID)
+		}
+	}
+	d.RemoveSynapse(0)
+	d.AddSynapse(back)
+	return d
+}
+
+func TestRecurrentSynapses(t *testing.T) {
+	d := recurrentTestDNA()
+	recurrent := d.recurrentSynapses()
+	if got, want := len(recurrent), 1; got != want {
+		t.Fatalf("Got %v, want %v", got, want)
+	}
+	for synID := range recurrent {
+		if got, want := d.Synpases.idMap[synID], (Synapse{src: 4, dst: 3}); got != want {
+			t.Errorf("Got %v, want %v", got, want)
+		}
+	}
+
+	if got, want := len(SimpleTestDNA().recurrentSynapses()), 0; got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+}
+
+func TestDOT(t *testing.T) {
+	dot := recurrentTestDNA().DOT()
+	for _, want := range []string{
+		"digraph dna {",
+		`n4 [label="I1\nSUBTRACT\n<12>"];`,
+		"{ rank=source; n0; n1; }",
+		"{ rank=sink; n2; }",
+		"n3 -> n4;",
+		"n4 -> n3 [style=dashed, constraint=false];",
+	} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("Want %q in:\n%s", want, dot)
+		}
+	}
+	if strings.Contains(dot, "n0 -> n2") {
+		t.Errorf("Removed synapse shouldn't be in:\n%s", dot)
+	}
+}
+
+func TestDOTEscaping(t *testing.T) {
+	op, err := RegisterOperator(Operator{Name: `TEST_"QUOTED"\OP`, Identity: ZeroIdentity, Combine: ADD.Operator().Combine})
+	if err != nil {
+		t.Fatalf("Got error %v", err)
+	}
+	t.Cleanup(func() { registry.unregister(op) })
+
+	d := SimpleTestDNA()
+	d.Neurons[2].Op = op
+	if got, want := d.DOT(), `n2 [label="M0\nTEST_\"QUOTED\"\\OP", shape=house];`; !strings.Contains(got, want) {
+		t.Errorf("Want %q in:\n%s", want, got)
+	}
+}
+
+func TestConglomerateDOT(t *testing.T) {
+	d := SimpleTestDNA()
+	d.Source.AddInterNeuron(0)
+
+	dot := d.ConglomerateDOT()
+	for _, want := range []string{
+		"digraph conglomerate {",
+		`n3 [label="I0", color=gray80, fontcolor=gray60];`,
+		"n0 -> n2 [penwidth=2];",
+		"n0 -> n3 [color=gray80];",
+	} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("Want %q in:\n%s", want, dot)
+		}
+	}
+}
+
+func TestSVG(t *testing.T) {
+	d := recurrentTestDNA()
+	svg := d.SVG()
+
+	// The output has to be well formed XML.
+	decoder := xml.NewDecoder(strings.NewReader(svg))
+	for {
+		_, err := decoder.Token()
+		if err != nil {
+			if err != io.EOF {
+				t.Fatalf("Got error %v in:\n%s", err, svg)
+			}
+			break
+		}
+	}
+
+	if got, want := strings.Count(svg, "<rect"), len(d.Neurons); got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+	if got, want := strings.Count(svg, "stroke-dasharray"), 1; got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+	if !strings.Contains(svg, "&lt;12&gt;") {
+		t.Errorf("Want escaped seed in:\n%s", svg)
+	}
+
+	layers := d.svgLayers(d.recurrentSynapses())
+	if got, want := []int{layers[0], layers[1], layers[3], layers[4], layers[2]}, []int{0, 0, 1, 2, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+}
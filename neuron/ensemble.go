@@ -0,0 +1,356 @@
+package neuron
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Firer is anything that fires like a Brain, so that it can play a Game.
+// Brain, CompiledBrain and Ensemble are all Firers.
+type Firer[S Signal] interface {
+	Fire(inputs [][]S) [][]S
+}
+
+// Play plays the game until it's over, and returns its fitness.
+func Play[S Signal](game Game[S], f Firer[S]) ScoreType {
+	for !game.IsOver() {
+		game.Update(f.Fire(game.CurrentState()))
+	}
+	return game.Fitness()
+}
+
+// VoteMethod is an enum for how an Ensemble combines the outputs of its
+// members.
+type VoteMethod int
+
+const (
+	// MAJORITY_VOTE gives the output string of each MOTOR neuron that the most
+	// members gave, or the first member's one if there's a tie.
+	MAJORITY_VOTE VoteMethod = iota
+	// MEDIAN_VOTE gives the median length of the output strings of each MOTOR
+	// neuron, with the median of each signal out of the members whose strings
+	// are long enough. The lower one is used when there are two medians.
+	MEDIAN_VOTE
+	// WEIGHTED_VOTE is the same as MAJORITY_VOTE, except each member counts
+	// as much as its weight.
+	WEIGHTED_VOTE
+)
+
+func (v VoteMethod) String() string {
+	return [...]string{"MAJORITY_VOTE", "MEDIAN_VOTE", "WEIGHTED_VOTE"}[v]
+}
+
+// EnsembleMember is a DNA along with its weight in a WEIGHTED_VOTE, which is
+// usually its fitness.
+type EnsembleMember[S Signal] struct {
+	DNA    *DNA[S]
+	Weight float64
+}
+
+// Ensemble fires a brain for every member and votes on their outputs. Only
+// outputs that were terminated get a vote, and a MOTOR is only left
+// unterminated if none of the members terminated it.
+type Ensemble[S Signal] struct {
+	brains  []*CompiledBrain[S]
+	weights []float64
+	method  VoteMethod
+}
+
+// NewEnsemble compiles a brain for each of the members, which all need the
+// same number of SENSE and MOTOR neurons. Negative weights count as 0, and if
+// none of the weights are above 0 then every member counts the same.
+func NewEnsemble[S Signal](method VoteMethod, members []EnsembleMember[S]) (*Ensemble[S], error) {
+	if len(members) == 0 {
+		return nil, fmt.Errorf("an ensemble needs at least one member")
+	}
+	if method < MAJORITY_VOTE || method > WEIGHTED_VOTE {
+		return nil, fmt.Errorf("unknown vote method %d", method)
+	}
+
+	e := &Ensemble[S]{
+		brains:  make([]*CompiledBrain[S], len(members)),
+		weights: make([]float64, len(members)),
+		method:  method,
+	}
+	total := 0.0
+	for i, member := range members {
+		plan := Compile(member.DNA)
+		if first := e.brains[0]; first != nil && (len(plan.sense) != len(first.plan.sense) || plan.numMotors != first.plan.numMotors) {
+			return nil, fmt.Errorf("member %d has %d inputs and %d outputs, but member 0 has %d and %d",
+				i, len(plan.sense), plan.numMotors, len(first.plan.sense), first.plan.numMotors)
+		}
+		e.brains[i] = plan.NewBrain()
+		if member.Weight > 0 {
+			e.weights[i] = member.Weight
+			total += member.Weight
+		}
+	}
+	if total == 0 {
+		for i := range e.weights {
+			e.weights[i] = 1
+		}
+	}
+	return e, nil
+}
+
+// Size is the number of members.
+func (e *Ensemble[S]) Size() int {
+	return len(e.brains)
+}
+
+// SetStepBudget sets the step budget of every member.
+func (e *Ensemble[S]) SetStepBudget(budget int) {
+	for _, brain := range e.brains {
+		brain.SetStepBudget(budget)
+	}
+}
+
+// SetCarryPolicy sets the carry policy of every member.
+func (e *Ensemble[S]) SetCarryPolicy(policy CarryPolicy) {
+	for _, brain := range e.brains {
+		brain.SetCarryPolicy(policy)
+	}
+}
+
+// Reset resets every member.
+func (e *Ensemble[S]) Reset() {
+	for _, brain := range e.brains {
+		brain.Reset()
+	}
+}
+
+// Firings is the total of the firings of every member.
+func (e *Ensemble[S]) Firings() int {
+	firings := 0
+	for _, brain := range e.brains {
+		firings += brain.Firings()
+	}
+	return firings
+}
+
+// Fire works the same as Brain.Fire, with the outputs voted on by the
+// members.
+func (e *Ensemble[S]) Fire(inputs [][]S) [][]S {
+	return e.FireResult(inputs).Outputs
+}
+
+// FireResult fires every member with the inputs, and votes on their outputs.
+// The Steps are the most steps any member took, and the Pending signals are
+// the total of all of the members.
+func (e *Ensemble[S]) FireResult(inputs [][]S) FireResult[S] {
+	results := make([]FireResult[S], len(e.brains))
+	for i, brain := range e.brains {
+		results[i] = brain.FireResult(inputs)
+	}
+
+	numMotors := e.brains[0].plan.numMotors
+	voted := FireResult[S]{
+		Outputs:    make([][]S, numMotors),
+		Terminated: make([]bool, numMotors),
+	}
+	for _, result := range results {
+		if result.Steps > voted.Steps {
+			voted.Steps = result.Steps
+		}
+		voted.Pending += result.Pending
+	}
+
+	for motorIndex := range voted.Outputs {
+		candidates := make([][]S, 0, len(results))
+		weights := make([]float64, 0, len(results))
+		for i, result := range results {
+			if result.Terminated[motorIndex] {
+				candidates = append(candidates, result.Outputs[motorIndex])
+				weights = append(weights, e.weights[i])
+			}
+		}
+
+		voted.Terminated[motorIndex] = len(candidates) > 0
+		if !voted.Terminated[motorIndex] {
+			voted.Outputs[motorIndex] = make([]S, 0)
+			voted.Exhausted = true
+			continue
+		}
+		switch e.method {
+		case MAJORITY_VOTE:
+			for i := range weights {
+				weights[i] = 1
+			}
+			voted.Outputs[motorIndex] = weightedVote(candidates, weights)
+		case MEDIAN_VOTE:
+			voted.Outputs[motorIndex] = medianVote(candidates)
+		case WEIGHTED_VOTE:
+			voted.Outputs[motorIndex] = weightedVote(candidates, weights)
+		}
+	}
+	return voted
+}
+
+// weightedVote returns the candidate with the most weight behind it, or the
+// first of them if there's a tie.
+func weightedVote[S Signal](candidates [][]S, weights []float64) []S {
+	// Each candidate's total is the weight of every candidate equal to it.
+	totals := make([]float64, len(candidates))
+	for i, candidate := range candidates {
+		for j, other := range candidates {
+			if equalSignals(candidate, other) {
+				totals[i] += weights[j]
+			}
+		}
+	}
+
+	best := 0
+	for i := range candidates {
+		if totals[i] > totals[best] {
+			best = i
+		}
+	}
+	return append(make([]S, 0, len(candidates[best])), candidates[best]...)
+}
+
+// medianVote builds the output one signal at a time from the median of the
+// candidates.
+func medianVote[S Signal](candidates [][]S) []S {
+	lengths := make([]int, len(candidates))
+	for i, candidate := range candidates {
+		lengths[i] = len(candidate)
+	}
+	sort.Ints(lengths)
+
+	output := make([]S, lengths[(len(lengths)-1)/2])
+	for index := range output {
+		signals := make([]S, 0, len(candidates))
+		for _, candidate := range candidates {
+			if index < len(candidate) {
+				signals = append(signals, candidate[index])
+			}
+		}
+		sort.Slice(signals, func(i, j int) bool {
+			return signals[i] < signals[j]
+		})
+		output[index] = signals[(len(signals)-1)/2]
+	}
+	return output
+}
+
+// HallOfFame keeps the highest weighted members ever added to it. A DNA that
+// has the same Hash as one already in the hall only replaces it if its weight
+// is higher, so the hall never holds two DNA that fire the same way.
+type HallOfFame[S Signal] struct {
+	size    int
+	members []EnsembleMember[S]
+	hashes  []DNAHash
+}
+
+// NewHallOfFame creates an empty hall that holds up to size members.
+func NewHallOfFame[S Signal](size int) *HallOfFame[S] {
+	return &HallOfFame[S]{
+		size: size,
+	}
+}
+
+// Add puts the member in the hall if it's one of the best so far, and returns
+// true if it was added.
+func (h *HallOfFame[S]) Add(member EnsembleMember[S]) bool {
+	hash := member.DNA.Hash()
+	for i := range h.members {
+		if h.hashes[i] != hash {
+			continue
+		}
+		if member.Weight <= h.members[i].Weight {
+			return false
+		}
+		h.members = append(h.members[:i], h.members[i+1:]...)
+		h.hashes = append(h.hashes[:i], h.hashes[i+1:]...)
+		break
+	}
+
+	// The members stay in order from the highest weight to the lowest, with
+	// the earliest added first when they're tied.
+	index := sort.Search(len(h.members), func(i int) bool {
+		return h.members[i].Weight < member.Weight
+	})
+	if index >= h.size {
+		return false
+	}
+	h.members = append(h.members[:index], append([]EnsembleMember[S]{member}, h.members[index:]...)...)
+	h.hashes = append(h.hashes[:index], append([]DNAHash{hash}, h.hashes[index:]...)...)
+	if len(h.members) > h.size {
+		h.members = h.members[:h.size]
+		h.hashes = h.hashes[:h.size]
+	}
+	return true
+}
+
+// Members returns the members of the hall, from the highest weight to the
+// lowest.
+func (h *HallOfFame[S]) Members() []EnsembleMember[S] {
+	return append([]EnsembleMember[S]{}, h.members...)
+}
+
+// SpeciesMembers returns the representative of each species in ID order,
+// weighted by its raw fitness.
+func (p *Playground[S]) SpeciesMembers() []EnsembleMember[S] {
+	speciesIDs := make([]IDType, 0, len(p.species))
+	for speciesID := range p.species {
+		speciesIDs = append(speciesIDs, speciesID)
+	}
+	sort.Ints(speciesIDs)
+
+	members := make([]EnsembleMember[S], len(speciesIDs))
+	for i, speciesID := range speciesIDs {
+		species := p.species[speciesID]
+		members[i] = EnsembleMember[S]{DNA: species.rep, Weight: float64(species.repFitness)}
+	}
+	return members
+}
+
+// TopMembers returns up to n of the DNA from the last generation the runner
+// played, from the highest raw fitness to the lowest. Raw fitness is used
+// since the costs are about the size of a brain, rather than how good its
+// answers are.
+func (r *Runner[S]) TopMembers(n int) []EnsembleMember[S] {
+	if n > len(r.ranked) {
+		n = len(r.ranked)
+	}
+	return append([]EnsembleMember[S]{}, r.ranked[:n]...)
+}
+
+// SpeciesMembers returns the representative of each species.
+func (r *Runner[S]) SpeciesMembers() []EnsembleMember[S] {
+	return r.play.SpeciesMembers()
+}
+
+// HallOfFame returns the best DNA of every generation so far, or nil if the
+// RunnerConfig doesn't keep a hall of fame.
+func (r *Runner[S]) HallOfFame() []EnsembleMember[S] {
+	if r.hallOfFame == nil {
+		return nil
+	}
+	return r.hallOfFame.Members()
+}
+
+// rank holds on to the DNA of the generation in order, since Evolve replaces
+// them, and adds the best of them to the hall of fame. They're all pinned in
+// the playground so compacting doesn't remove anything they use.
+func (r *Runner[S]) rank(results []BrainScore) {
+	sorted := append([]BrainScore{}, results...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].raw > sorted[j].raw
+	})
+
+	r.ranked = make([]EnsembleMember[S], len(sorted))
+	for i, result := range sorted {
+		r.ranked[i] = EnsembleMember[S]{DNA: r.play.codes[result.id], Weight: float64(result.raw)}
+	}
+	if r.hallOfFame != nil {
+		for _, member := range r.TopMembers(r.hallOfFame.size) {
+			r.hallOfFame.Add(member)
+		}
+	}
+
+	r.play.pinned = make([]*DNA[S], 0, len(r.ranked)+len(r.HallOfFame()))
+	for _, member := range append(r.HallOfFame(), r.ranked...) {
+		r.play.pinned = append(r.play.pinned, member.DNA)
+	}
+}
@@ -0,0 +1,205 @@
+package neuron
+
+import (
+	"reflect"
+	"testing"
+)
+
+// ensembleTestMembers fire 3, nothing and 3 for the inputs {1}, {2}, since a
+// 0 terminates the output.
+func ensembleTestMembers() []EnsembleMember[SignalType] {
+	members := make([]EnsembleMember[SignalType], 0)
+	for _, op := range []OperatorType{OR, AND, XOR} {
+		d := SimpleTestDNA()
+		d.Neurons[2].Op = op
+		members = append(members, EnsembleMember[SignalType]{DNA: d, Weight: 1})
+	}
+	return members
+}
+
+func TestEnsembleVote(t *testing.T) {
+	for _, test := range []struct {
+		method VoteMethod
+		weight float64
+		want   [][]SignalType
+	}{
+		{MAJORITY_VOTE, 1, [][]SignalType{{3}}},
+		{MAJORITY_VOTE, 10, [][]SignalType{{3}}},
+		{MEDIAN_VOTE, 10, [][]SignalType{{3}}},
+		{WEIGHTED_VOTE, 1, [][]SignalType{{3}}},
+		{WEIGHTED_VOTE, 10, [][]SignalType{{}}},
+	} {
+		members := ensembleTestMembers()
+		members[1].Weight = test.weight
+		e, err := NewEnsemble(test.method, members)
+		if err != nil {
+			t.Fatalf("Got error %v", err)
+		}
+		if got := e.Fire([][]SignalType{{1}, {2}}); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%v with weight %v: got %v, want %v", test.method, test.weight, got, test.want)
+		}
+	}
+}
+
+func TestVotes(t *testing.T) {
+	candidates := [][]SignalType{{1, 2}, {5}, {1, 2}, {3, 4, 9}}
+	if got, want := weightedVote(candidates, []float64{1, 1, 1, 1}), []SignalType{1, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+	if got, want := weightedVote(candidates, []float64{1, 3, 1, 0}), []SignalType{5}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+	// Ties go to the first candidate.
+	if got, want := weightedVote(candidates, []float64{1, 2, 1, 2}), []SignalType{1, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+
+	// The median length is 2, then the medians of 1, 5, 1, 3 and 2, 2, 4.
+	if got, want := medianVote(candidates), []SignalType{1, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+	if got, want := medianVote([][]SignalType{{9}, {}, {4, 8}}), []SignalType{4}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+}
+
+func TestEnsembleTerminated(t *testing.T) {
+	// TRUTH never sends the 0 that terminates an output.
+	members := ensembleTestMembers()
+	members[0].DNA.Neurons[2].Op = TRUTH
+	members[1].DNA.Neurons[2].Op = TRUTH
+	e, err := NewEnsemble(MAJORITY_VOTE, members)
+	if err != nil {
+		t.Fatalf("Got error %v", err)
+	}
+
+	// The unterminated members don't get a vote, so XOR wins.
+	result := e.FireResult([][]SignalType{{1}, {2}})
+	if got, want := result.Outputs, [][]SignalType{{3}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+	if got, want := result.Exhausted, false; got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+
+	members[2].DNA.Neurons[2].Op = TRUTH
+	if e, err = NewEnsemble(MAJORITY_VOTE, members); err != nil {
+		t.Fatalf("Got error %v", err)
+	}
+	result = e.FireResult([][]SignalType{{1}, {2}})
+	if got, want := result.Terminated, []bool{false}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+	if got, want := result.Exhausted, true; got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+}
+
+func TestNewEnsembleErrors(t *testing.T) {
+	if _, err := NewEnsemble[SignalType](MAJORITY_VOTE, nil); err == nil {
+		t.Errorf("Want an error for no members")
+	}
+	if _, err := NewEnsemble(VoteMethod(5), ensembleTestMembers()); err == nil {
+		t.Errorf("Want an error for an unknown vote method")
+	}
+
+	c := NewConglomerate()
+	c.AddVisionAndMotor(1, 1)
+	d := NewDNA[SignalType](c)
+	d.AddNeuron(0, OR)
+	d.AddNeuron(1, OR)
+	members := append(ensembleTestMembers(), EnsembleMember[SignalType]{DNA: d})
+	if _, err := NewEnsemble(MAJORITY_VOTE, members); err == nil {
+		t.Errorf("Want an error for members with different inputs")
+	}
+}
+
+func TestEnsemblePlay(t *testing.T) {
+	// An ensemble of the same DNA plays just like one brain.
+	members := ensembleTestMembers()
+	for i := range members {
+		members[i].DNA = SimpleTestDNA()
+	}
+	e, err := NewEnsemble(MEDIAN_VOTE, members)
+	if err != nil {
+		t.Fatalf("Got error %v", err)
+	}
+	if got, want := Play[SignalType](&testGame{turn: 1}, e), Play[SignalType](&testGame{turn: 1}, Flourish(SimpleTestDNA())); got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+	if got, want := e.Firings(), 3*Flourish(SimpleTestDNA()).Firings(); got < want {
+		t.Errorf("Got %v, want at least %v", got, want)
+	}
+}
+
+func TestHallOfFame(t *testing.T) {
+	h := NewHallOfFame[SignalType](2)
+	members := ensembleTestMembers()
+	for i, weight := range []float64{5, 1, 7} {
+		members[i].Weight = weight
+	}
+	for i, want := range []bool{true, true, true} {
+		if got := h.Add(members[i]); got != want {
+			t.Errorf("Member %d: got %v, want %v", i, got, want)
+		}
+	}
+	if got, want := h.Members(), []EnsembleMember[SignalType]{members[2], members[0]}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+
+	// A DNA that fires the same only replaces its twin with a higher weight.
+	twin := EnsembleMember[SignalType]{DNA: members[0].DNA.DeepCopy(), Weight: 5}
+	if got, want := h.Add(twin), false; got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+	twin.Weight = 8
+	if got, want := h.Add(twin), true; got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+	if got, want := h.Members(), []EnsembleMember[SignalType]{twin, members[2]}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+	if got, want := h.Add(members[1]), false; got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+}
+
+func TestRunnerEnsembles(t *testing.T) {
+	config := createTestRunner().config
+	config.HallOfFame = 3
+	runner := NewRunner(config)
+	runner.play.InitDNA()
+	for gen := 0; gen < 3; gen++ {
+		runner.runGeneration(gen)
+	}
+
+	top := runner.TopMembers(4)
+	if got, want := len(top), 4; got != want {
+		t.Fatalf("Got %v, want %v", got, want)
+	}
+	for i := 1; i < len(top); i++ {
+		if top[i].Weight > top[i-1].Weight {
+			t.Errorf("Got member %d weighted %v over %v", i, top[i].Weight, top[i-1].Weight)
+		}
+	}
+	if got, want := len(runner.TopMembers(1000)), runner.play.config.NumVariants; got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+
+	// The hall can have fewer if the best DNA all fire the same.
+	hall := runner.HallOfFame()
+	if got, want := len(hall) >= 1 && len(hall) <= 3, true; got != want {
+		t.Fatalf("Got %v members, want 1 to 3", len(hall))
+	}
+	if got, want := hall[0].Weight >= top[0].Weight, true; got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+
+	for _, members := range [][]EnsembleMember[SignalType]{top, hall, runner.SpeciesMembers()} {
+		e, err := NewEnsemble(WEIGHTED_VOTE, members)
+		if err != nil {
+			t.Fatalf("Got error %v", err)
+		}
+		Play[SignalType](runner.config.NewGameFn(), e)
+	}
+}
@@ -0,0 +1,150 @@
+package neuron
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+)
+
+// DNAHash identifies what a DNA does, so two DNA with the same hash fire the
+// same way.
+type DNAHash [sha256.Size]byte
+
+func (h DNAHash) String() string {
+	return hex.EncodeToString(h[:])
+}
+
+// Hash returns the canonical hash of the DNA, which covers the genes of every
+// neuron and the synapses between them. It's taken from the pruned DNA, so
+// structure that can't make a difference to the MOTOR neurons doesn't change
+// the hash. Since IDs come from the shared Conglomerate, the same genome
+// always has the same hash no matter how it came about.
+func (d *DNA[S]) Hash() DNAHash {
+	pruned := d.Prune()
+
+	var buf bytes.Buffer
+	w := WidthOf[S]()
+	writeUvarint(&buf, uint64(w.Bits))
+	writeUvarint(&buf, uint64(w.FracBits))
+
+	neuronIDs := sortedNeuronIDs(pruned)
+	writeUvarint(&buf, uint64(len(neuronIDs)))
+	for _, neuronID := range neuronIDs {
+		neuron := pruned.Neurons[neuronID]
+		writeUvarint(&buf, uint64(neuronID))
+		// Ops are hashed by name so the hash doesn't depend on the order they
+		// were registered in.
+		name := neuron.Op.String()
+		writeUvarint(&buf, uint64(len(name)))
+		buf.WriteString(name)
+		writeUvarint(&buf, uint64(neuron.Kind))
+		if neuron.HasSeed {
+			buf.WriteByte(1)
+			writeUvarint(&buf, uint64(neuron.Seed))
+		} else {
+			buf.WriteByte(0)
+		}
+		writeVarint(&buf, int64(neuron.Threshold))
+		writeUvarint(&buf, uint64(neuron.Refractory))
+		// Like IsEquiv, a table only makes a difference to a TABLE neuron.
+		if neuron.Kind == TABLE {
+			writeUvarint(&buf, uint64(len(neuron.Table)))
+			for _, entry := range neuron.Table {
+				writeUvarint(&buf, uint64(entry))
+			}
+		}
+	}
+
+	// The synapse IDs are part of the hash since they decide the order of the
+	// inputs to each neuron.
+	synIDs := sortedSynapseIDs(pruned.Synpases)
+	writeUvarint(&buf, uint64(len(synIDs)))
+	for _, synID := range synIDs {
+		syn := pruned.Synpases.idMap[synID]
+		writeUvarint(&buf, uint64(synID))
+		writeUvarint(&buf, uint64(syn.src))
+		writeUvarint(&buf, uint64(syn.dst))
+	}
+	return sha256.Sum256(buf.Bytes())
+}
+
+// FitnessCacheStats counts how often a game was skipped because the DNA had
+// already played it.
+type FitnessCacheStats struct {
+	Hits   int
+	Misses int
+	// Entries is the number of genomes in the cache.
+	Entries int
+}
+
+// HitRate is the fraction of lookups that were hits, or 0 without any.
+func (s FitnessCacheStats) HitRate() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+type fitnessEntry struct {
+	raw ScoreType
+	// gen is the last generation the entry was used in.
+	gen int
+}
+
+// fitnessCache holds the raw fitness of each genome by its hash. It's used by
+// the game simulations at the same time, so everything is behind the lock.
+type fitnessCache struct {
+	mu      sync.Mutex
+	entries map[DNAHash]*fitnessEntry
+	gen     int
+	stats   FitnessCacheStats
+	total   FitnessCacheStats
+}
+
+func newFitnessCache() *fitnessCache {
+	return &fitnessCache{
+		entries: make(map[DNAHash]*fitnessEntry),
+	}
+}
+
+func (c *fitnessCache) get(hash DNAHash) (ScoreType, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[hash]
+	if !ok {
+		c.stats.Misses++
+		return 0, false
+	}
+	entry.gen = c.gen
+	c.stats.Hits++
+	return entry.raw, true
+}
+
+func (c *fitnessCache) put(hash DNAHash, raw ScoreType) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[hash] = &fitnessEntry{raw: raw, gen: c.gen}
+}
+
+// endGeneration returns the stats for the generation and starts the next one.
+// Genomes that weren't played this generation are dropped, since only the
+// ones carried forward by elitism and crossover are likely to come back.
+func (c *fitnessCache) endGeneration() FitnessCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for hash, entry := range c.entries {
+		if entry.gen != c.gen {
+			delete(c.entries, hash)
+		}
+	}
+
+	stats := c.stats
+	stats.Entries = len(c.entries)
+	c.total.Hits += stats.Hits
+	c.total.Misses += stats.Misses
+	c.total.Entries = stats.Entries
+	c.stats = FitnessCacheStats{}
+	c.gen++
+	return stats
+}
@@ -0,0 +1,98 @@
+package neuron
+
+import (
+	"testing"
+)
+
+func TestDNAHash(t *testing.T) {
+	d := SimpleTestDNA()
+	if got, want := d.DeepCopy().Hash(), d.Hash(); got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+
+	// A neuron that doesn't reach the MOTOR can't change what the DNA does.
+	dead := d.DeepCopy()
+	deadID := dead.Source.AddInterNeuron(0)
+	dead.AddNeuron(deadID, XOR)
+	dead.AddSynapse(dead.Source.Synapses.nextID - 2)
+	if got, want := dead.Hash(), d.Hash(); got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+
+	// Neither does a table left over on a neuron that isn't a TABLE anymore.
+	stale := d.DeepCopy()
+	stale.Neurons[2].Table = make([]SignalType, TableSize)
+	stale.Neurons[2].Table[0] = 7
+	if got, want := stale.Hash(), d.Hash(); got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+
+	table := make([]SignalType, TableSize)
+	for _, test := range []struct {
+		name   string
+		mutate func(d *DNA[SignalType])
+	}{
+		{"op", func(d *DNA[SignalType]) { d.Neurons[2].Op = AND }},
+		{"seed", func(d *DNA[SignalType]) { d.SetSeed(0, 1) }},
+		{"no seed", func(d *DNA[SignalType]) { d.RemoveSeed(0) }},
+		{"threshold", func(d *DNA[SignalType]) { d.Neurons[2].Threshold = AllInputs }},
+		{"synapse", func(d *DNA[SignalType]) { d.RemoveSynapse(1) }},
+		{"table", func(d *DNA[SignalType]) { d.Neurons[2].SetTable(table) }},
+	} {
+		changed := d.DeepCopy()
+		test.mutate(changed)
+		if changed.Hash() == d.Hash() {
+			t.Errorf("%s: want a different hash", test.name)
+		}
+	}
+}
+
+func TestRunnerFitnessCache(t *testing.T) {
+	uncached := createTestRunner()
+	uncached.play.InitDNA()
+	uncached.play.codes[0] = SimpleTestDNA()
+	resChan := make(chan BrainScore)
+	go uncached.gameSimulation(0, resChan)
+	want := <-resChan
+
+	runner := createTestRunner()
+	runner.config.CacheFitness = true
+	runner.play.InitDNA()
+	runner.play.codes[0] = SimpleTestDNA()
+	runner.play.codes[1] = SimpleTestDNA()
+	for _, id := range []IDType{0, 1} {
+		go runner.gameSimulation(id, resChan)
+		got := <-resChan
+		want.id = id
+		if got != want {
+			t.Errorf("Got %+v, want %+v", got, want)
+		}
+	}
+	if got, want := runner.cache.endGeneration(), (FitnessCacheStats{Hits: 1, Misses: 1, Entries: 1}); got != want {
+		t.Errorf("Got %+v, want %+v", got, want)
+	}
+
+	// Genomes are dropped once a generation goes by without them.
+	if got, want := runner.cache.endGeneration().Entries, 0; got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+
+	for gen := 0; gen < 3; gen++ {
+		runner.runGeneration(gen)
+	}
+	// Every round after the first plays the same genomes again.
+	stats := runner.CacheStats()
+	if got, want := stats.Hits+stats.Misses, 1+1+3*runner.config.Rounds*runner.play.config.NumVariants; got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+	if got, want := stats.Hits >= 3*runner.play.config.NumVariants, true; got != want {
+		t.Errorf("Got %v, want %v: %+v", got, want, stats)
+	}
+
+	// Charging for firings turns the cache off.
+	runner.config.Costs.Firing = 1
+	runner.runGeneration(3)
+	if got, want := runner.CacheStats(), stats; got != want {
+		t.Errorf("Got %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,455 @@
+package neuron
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// JSONVersion is the version of the JSON format written by this package.
+// Loading JSON from a newer version is an error instead of silently dropping
+// whatever was added since.
+const JSONVersion = 1
+
+func checkJSONVersion(version int) error {
+	if version < 1 || version > JSONVersion {
+		return fmt.Errorf("unsupported JSON version %d, want 1 to %d", version, JSONVersion)
+	}
+	return nil
+}
+
+// MarshalJSON writes the IDs as a list in index order.
+func (x *IndexedIDs) MarshalJSON() ([]byte, error) {
+	ids := make([]IDType, x.Length())
+	for index := range ids {
+		ids[index] = x.GetID(index)
+	}
+	return json.Marshal(ids)
+}
+
+// UnmarshalJSON replaces the IDs with a list in index order.
+func (x *IndexedIDs) UnmarshalJSON(data []byte) error {
+	var ids []IDType
+	if err := json.Unmarshal(data, &ids); err != nil {
+		return err
+	}
+
+	*x = *NewIndexedIDs()
+	for _, id := range ids {
+		if x.HasID(id) {
+			return fmt.Errorf("duplicate indexed id %d", id)
+		}
+		x.InsertID(id)
+	}
+	return nil
+}
+
+type synapseJSON struct {
+	ID  IDType `json:"id"`
+	Src IDType `json:"src"`
+	Dst IDType `json:"dst"`
+}
+
+type synapseTrackerJSON struct {
+	NextID   IDType        `json:"next_id"`
+	Synapses []synapseJSON `json:"synapses"`
+}
+
+// MarshalJSON writes the synapses in ID order, along with the next ID so that
+// IDs of removed synapses aren't handed out again after loading.
+func (s *SynapseTracker) MarshalJSON() ([]byte, error) {
+	out := synapseTrackerJSON{
+		NextID:   s.nextID,
+		Synapses: make([]synapseJSON, 0, len(s.idMap)),
+	}
+	for synID, syn := range s.idMap {
+		out.Synapses = append(out.Synapses, synapseJSON{ID: synID, Src: syn.src, Dst: syn.dst})
+	}
+	sort.Slice(out.Synapses, func(i, j int) bool {
+		return out.Synapses[i].ID < out.Synapses[j].ID
+	})
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON replaces the synapses, rebuilding the srcMap from them.
+func (s *SynapseTracker) UnmarshalJSON(data []byte) error {
+	var in synapseTrackerJSON
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+
+	tracker := NewSynapseTracker()
+	for _, syn := range in.Synapses {
+		if _, exists := tracker.idMap[syn.ID]; exists {
+			return fmt.Errorf("duplicate synapse id %d", syn.ID)
+		}
+		tracker.TrackSynapse(syn.ID, syn.Src, syn.Dst)
+	}
+	if in.NextID > tracker.nextID {
+		tracker.nextID = in.NextID
+	}
+	*s = *tracker
+	return nil
+}
+
+type conglomerateJSON struct {
+	Version  int             `json:"version"`
+	Sense    *IndexedIDs     `json:"sense"`
+	Inter    *IndexedIDs     `json:"inter"`
+	Motor    *IndexedIDs     `json:"motor"`
+	Synapses *SynapseTracker `json:"synapses"`
+	// NextNeuronID can be past the highest neuron ID once the conglomerate has
+	// been compacted.
+	NextNeuronID IDType `json:"next_neuron_id,omitempty"`
+}
+
+// MarshalJSON writes the neuron IDs of each type along with every synapse.
+func (c *Conglomerate) MarshalJSON() ([]byte, error) {
+	return json.Marshal(conglomerateJSON{
+		Version:      JSONVersion,
+		Sense:        c.NeuronIDs[SENSE],
+		Inter:        c.NeuronIDs[INTER],
+		Motor:        c.NeuronIDs[MOTOR],
+		Synapses:     c.Synapses,
+		NextNeuronID: c.nextNeuronID,
+	})
+}
+
+// UnmarshalJSON replaces the whole conglomerate.
+func (c *Conglomerate) UnmarshalJSON(data []byte) error {
+	in := conglomerateJSON{
+		Sense:    NewIndexedIDs(),
+		Inter:    NewIndexedIDs(),
+		Motor:    NewIndexedIDs(),
+		Synapses: NewSynapseTracker(),
+	}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	if err := checkJSONVersion(in.Version); err != nil {
+		return err
+	}
+
+	loaded := &Conglomerate{
+		NeuronIDs: map[NeuronType]*IndexedIDs{
+			SENSE: in.Sense,
+			INTER: in.Inter,
+			MOTOR: in.Motor,
+		},
+		Synapses:     in.Synapses,
+		nextNeuronID: in.NextNeuronID,
+	}
+	seen := make(IDSet)
+	for _, nType := range NeuronTypes {
+		for id := range loaded.NeuronIDs[nType].IDToIndex {
+			if _, ok := seen[id]; ok {
+				return fmt.Errorf("neuron %d has more than one type", id)
+			}
+			seen[id] = member
+			if id >= loaded.nextNeuronID {
+				loaded.nextNeuronID = id + 1
+			}
+		}
+	}
+	for synID, syn := range loaded.Synapses.idMap {
+		_, srcOK := seen[syn.src]
+		_, dstOK := seen[syn.dst]
+		if !srcOK || !dstOK {
+			return fmt.Errorf("synapse %d connects unknown neurons src=%d,dst=%d", synID, syn.src, syn.dst)
+		}
+	}
+
+	*c = *loaded
+	return nil
+}
+
+// hasNeuron returns true if the neuron ID is tracked under any type.
+func (c *Conglomerate) hasNeuron(id IDType) bool {
+	for _, nType := range NeuronTypes {
+		if c.NeuronIDs[nType].HasID(id) {
+			return true
+		}
+	}
+	return false
+}
+
+// neuronJSON stores ops and kinds by name, so that saved neurons don't depend
+// on the order operators were registered in.
+type neuronJSON[S Signal] struct {
+	Op    string `json:"op"`
+	Kind  string `json:"kind"`
+	Seed  *S     `json:"seed,omitempty"`
+	Table []S    `json:"table,omitempty"`
+	// Threshold is a pointer so that leaving it out gives the DefaultThreshold
+	// instead of 0.
+	Threshold  *int `json:"threshold"`
+	Refractory int  `json:"refractory,omitempty"`
+}
+
+// MarshalJSON writes the neuron, leaving out the seed if it isn't set.
+func (n *Neuron[S]) MarshalJSON() ([]byte, error) {
+	out := neuronJSON[S]{
+		Op:         n.Op.String(),
+		Kind:       n.Kind.String(),
+		Table:      n.Table,
+		Threshold:  &n.Threshold,
+		Refractory: n.Refractory,
+	}
+	if n.HasSeed {
+		seed := n.Seed
+		out.Seed = &seed
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON replaces the neuron. The op has to be registered under the
+// same name it was saved with.
+func (n *Neuron[S]) UnmarshalJSON(data []byte) error {
+	var in neuronJSON[S]
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+
+	op, ok := LookupOperator(in.Op)
+	if !ok {
+		return fmt.Errorf("unregistered operator %q", in.Op)
+	}
+	kind, err := parseNeuronKind(in.Kind)
+	if err != nil {
+		return err
+	}
+	// Only TABLE neurons use a table, so any other would be stale.
+	if in.Table != nil && kind != TABLE {
+		return fmt.Errorf("%v neuron has a table", kind)
+	}
+	if in.Table != nil && len(in.Table) != TableSize {
+		return fmt.Errorf("table has %d entries instead of %d", len(in.Table), TableSize)
+	}
+
+	loaded := NewNeuron[S](op)
+	loaded.Kind = kind
+	loaded.Table = in.Table
+	if in.Threshold != nil {
+		loaded.Threshold = *in.Threshold
+	}
+	loaded.Refractory = in.Refractory
+	if in.Seed != nil {
+		loaded.SetSeed(*in.Seed)
+	}
+	*n = *loaded
+	return nil
+}
+
+func parseNeuronKind(name string) (NeuronKind, error) {
+	for _, kind := range NeuronKinds {
+		if kind.String() == name {
+			return kind, nil
+		}
+	}
+	return PURE, fmt.Errorf("unknown neuron kind %q", name)
+}
+
+type dnaJSON[S Signal] struct {
+	Version int                   `json:"version"`
+	Neurons map[IDType]*Neuron[S] `json:"neurons"`
+	// Synapses are only stored by ID, since the Source has the rest.
+	Synapses []IDType `json:"synapses"`
+}
+
+// MarshalJSON writes the neurons and synapse IDs of the DNA, but not its
+// Source, which is usually shared with many other DNA.
+func (d *DNA[S]) MarshalJSON() ([]byte, error) {
+	out := dnaJSON[S]{
+		Version:  JSONVersion,
+		Neurons:  d.Neurons,
+		Synapses: make([]IDType, 0, len(d.Synpases.idMap)),
+	}
+	for synID := range d.Synpases.idMap {
+		out.Synapses = append(out.Synapses, synID)
+	}
+	sort.Ints(out.Synapses)
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON replaces the neurons and synapses of the DNA. The Source has
+// to be set beforehand, such as with NewDNA, and every neuron and synapse
+// has to exist in it.
+func (d *DNA[S]) UnmarshalJSON(data []byte) error {
+	if d.Source == nil {
+		return fmt.Errorf("DNA needs a Source conglomerate to unmarshal into")
+	}
+
+	var in dnaJSON[S]
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	if err := checkJSONVersion(in.Version); err != nil {
+		return err
+	}
+
+	loaded := NewDNA[S](d.Source)
+	for neuronID, neuron := range in.Neurons {
+		if !d.Source.hasNeuron(neuronID) {
+			return fmt.Errorf("neuron %d isn't in the source conglomerate", neuronID)
+		}
+		if neuron == nil {
+			return fmt.Errorf("neuron %d is null", neuronID)
+		}
+		loaded.Neurons[neuronID] = neuron
+	}
+	for _, synID := range in.Synapses {
+		if _, ok := d.Source.Synapses.idMap[synID]; !ok {
+			return fmt.Errorf("synapse %d isn't in the source conglomerate", synID)
+		}
+		loaded.AddSynapse(synID)
+	}
+	*d = *loaded
+	return nil
+}
+
+type genomeJSON struct {
+	Version      int             `json:"version"`
+	Conglomerate *Conglomerate   `json:"conglomerate"`
+	DNA          json.RawMessage `json:"dna"`
+}
+
+// MarshalGenome writes the DNA along with its Source, which is everything
+// needed to Flourish it again later.
+func MarshalGenome[S Signal](dna *DNA[S]) ([]byte, error) {
+	dnaData, err := json.Marshal(dna)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(genomeJSON{
+		Version:      JSONVersion,
+		Conglomerate: dna.Source,
+		DNA:          dnaData,
+	})
+}
+
+// UnmarshalGenome loads a DNA written by MarshalGenome, with its own copy of
+// the Conglomerate.
+func UnmarshalGenome[S Signal](data []byte) (*DNA[S], error) {
+	in := genomeJSON{
+		Conglomerate: NewConglomerate(),
+	}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return nil, err
+	}
+	if err := checkJSONVersion(in.Version); err != nil {
+		return nil, err
+	}
+
+	dna := NewDNA[S](in.Conglomerate)
+	if err := json.Unmarshal(in.DNA, dna); err != nil {
+		return nil, err
+	}
+	return dna, nil
+}
+
+type opWeightJSON struct {
+	Op     string  `json:"op"`
+	Weight float64 `json:"weight"`
+}
+
+type playgroundJSON struct {
+	Version      int                        `json:"version"`
+	Conglomerate *Conglomerate              `json:"conglomerate"`
+	Codes        map[IDType]json.RawMessage `json:"codes"`
+	// Species are stored by their representative, since the scores only last
+	// for a single Evolve. The raw fitness of each representative is kept
+	// separately.
+	Species        map[IDType]json.RawMessage `json:"species"`
+	SpeciesFitness map[IDType]ScoreType       `json:"species_fitness,omitempty"`
+	OpWeights      []opWeightJSON             `json:"op_weights,omitempty"`
+	// Generations is the number of calls to Evolve so far, which decides when
+	// the conglomerate is compacted.
+	Generations int `json:"generations,omitempty"`
+}
+
+// MarshalJSON writes the state of the evolution, which is the conglomerate,
+// every DNA, the species, any adapted op weights and the number of
+// generations. The config isn't included, and comes from the playground
+// that's unmarshaled into.
+func (p *Playground[S]) MarshalJSON() ([]byte, error) {
+	out := playgroundJSON{
+		Version:        JSONVersion,
+		Conglomerate:   p.source,
+		Codes:          make(map[IDType]json.RawMessage, len(p.codes)),
+		Species:        make(map[IDType]json.RawMessage, len(p.species)),
+		SpeciesFitness: make(map[IDType]ScoreType, len(p.species)),
+		Generations:    p.generations,
+	}
+	for id, dna := range p.codes {
+		data, err := json.Marshal(dna)
+		if err != nil {
+			return nil, err
+		}
+		out.Codes[id] = data
+	}
+	for id, species := range p.species {
+		data, err := json.Marshal(species.rep)
+		if err != nil {
+			return nil, err
+		}
+		out.Species[id] = data
+		out.SpeciesFitness[id] = species.repFitness
+	}
+	for i, op := range p.opChoices {
+		out.OpWeights = append(out.OpWeights, opWeightJSON{Op: op.String(), Weight: p.opWeights[i]})
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON replaces the state of the evolution, so that a run can pick
+// up where it left off.
+func (p *Playground[S]) UnmarshalJSON(data []byte) error {
+	in := playgroundJSON{
+		Conglomerate: NewConglomerate(),
+	}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	if err := checkJSONVersion(in.Version); err != nil {
+		return err
+	}
+
+	codes := make(map[IDType]*DNA[S], len(in.Codes))
+	for id, dnaData := range in.Codes {
+		dna := NewDNA[S](in.Conglomerate)
+		if err := json.Unmarshal(dnaData, dna); err != nil {
+			return fmt.Errorf("DNA %d: %v", id, err)
+		}
+		codes[id] = dna
+	}
+	species := make(map[IDType]*Species[S], len(in.Species))
+	for id, repData := range in.Species {
+		rep := NewDNA[S](in.Conglomerate)
+		if err := json.Unmarshal(repData, rep); err != nil {
+			return fmt.Errorf("species %d: %v", id, err)
+		}
+		species[id] = &Species[S]{
+			rep:        rep,
+			scores:     make([]BrainScore, 0),
+			repFitness: in.SpeciesFitness[id],
+		}
+	}
+	var opChoices []OperatorType
+	var opWeights []float64
+	for _, weight := range in.OpWeights {
+		op, ok := LookupOperator(weight.Op)
+		if !ok {
+			return fmt.Errorf("unregistered operator %q", weight.Op)
+		}
+		opChoices = append(opChoices, op)
+		opWeights = append(opWeights, weight.Weight)
+	}
+
+	p.source = in.Conglomerate
+	p.codes = codes
+	p.species = species
+	p.opChoices = opChoices
+	p.opWeights = opWeights
+	p.generations = in.Generations
+	return nil
+}
@@ -0,0 +1,200 @@
+package neuron
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestIndexedIDsJSON(t *testing.T) {
+	x := NewIndexedIDs()
+	x.InsertID(5)
+	x.InsertID(2)
+	x.InsertID(9)
+
+	data, err := json.Marshal(x)
+	if err != nil {
+		t.Fatalf("Got error %v", err)
+	}
+	if got, want := string(data), "[5,2,9]"; got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+
+	loaded := NewIndexedIDs()
+	if err := json.Unmarshal(data, loaded); err != nil {
+		t.Fatalf("Got error %v", err)
+	}
+	if !EqualIndexedIDs(loaded, x) {
+		t.Errorf("Got %+v, want %+v", loaded, x)
+	}
+
+	if err := json.Unmarshal([]byte("[1,1]"), loaded); err == nil {
+		t.Errorf("Want error for duplicate IDs, got none")
+	}
+}
+
+func TestSynapseTrackerJSON(t *testing.T) {
+	s := NewSynapseTracker()
+	s.AddNewSynapse(0, 1)
+	s.AddNewSynapse(1, 2)
+	s.AddNewSynapse(0, 2)
+	s.RemoveSynapse(2)
+
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatalf("Got error %v", err)
+	}
+	loaded := NewSynapseTracker()
+	if err := json.Unmarshal(data, loaded); err != nil {
+		t.Fatalf("Got error %v", err)
+	}
+	if !reflect.DeepEqual(loaded, s) {
+		t.Errorf("Got %+v, want %+v", loaded, s)
+	}
+	// The ID of the removed synapse isn't used again.
+	if got, want := loaded.AddNewSynapse(2, 0), 3; got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+}
+
+func TestGenomeJSON(t *testing.T) {
+	d := SimpleTestDNA()
+	d.Neurons[0].Op = SUBTRACT
+	d.Neurons[1].Kind = ACCUMULATOR
+	d.Neurons[1].Refractory = 1
+	d.Neurons[2].Threshold = AllInputs
+	d.RemoveSeed(1)
+
+	data, err := MarshalGenome(d)
+	if err != nil {
+		t.Fatalf("Got error %v", err)
+	}
+	if !strings.Contains(string(data), `"op":"SUBTRACT"`) {
+		t.Errorf("Want ops stored by name, got %s", data)
+	}
+
+	loaded, err := UnmarshalGenome[SignalType](data)
+	if err != nil {
+		t.Fatalf("Got error %v", err)
+	}
+	if got, want := loaded.PrettyPrint(), d.PrettyPrint(); got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+	for id, neuron := range d.Neurons {
+		if !loaded.Neurons[id].IsEquiv(neuron) {
+			t.Errorf("Neuron %d: got %+v, want %+v", id, loaded.Neurons[id], neuron)
+		}
+	}
+	if !reflect.DeepEqual(loaded.Synpases, d.Synpases) {
+		t.Errorf("Got %+v, want %+v", loaded.Synpases, d.Synpases)
+	}
+
+	// The loaded genome is ready to use.
+	inputs := [][]SignalType{{9}, {2}}
+	if got, want := Flourish(loaded).Fire(inputs), Flourish(d).Fire(inputs); !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+}
+
+func TestDNAJSONErrors(t *testing.T) {
+	d := SimpleTestDNA()
+	data, err := json.Marshal(d)
+	if err != nil {
+		t.Fatalf("Got error %v", err)
+	}
+
+	if err := json.Unmarshal(data, &DNA[SignalType]{}); err == nil {
+		t.Errorf("Want error without a source, got none")
+	}
+	if err := json.Unmarshal(data, NewDNA[SignalType](NewConglomerate())); err == nil {
+		t.Errorf("Want error for neurons missing from the source, got none")
+	}
+
+	future := strings.Replace(string(data), `"version":1`, `"version":99`, 1)
+	if err := json.Unmarshal([]byte(future), NewDNA[SignalType](d.Source)); err == nil {
+		t.Errorf("Want error for a newer version, got none")
+	}
+
+	// Leaving out the threshold gives the default, rather than 0.
+	noThreshold := strings.Replace(string(data), `,"threshold":2`, ``, -1)
+	loaded := NewDNA[SignalType](d.Source)
+	if err := json.Unmarshal([]byte(noThreshold), loaded); err != nil {
+		t.Fatalf("Got error %v", err)
+	}
+	if got, want := loaded.Neurons[2].Threshold, DefaultThreshold; got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+
+	unknownOp := strings.Replace(string(data), `"op":"OR"`, `"op":"NOT_AN_OP"`, 1)
+	if err := json.Unmarshal([]byte(unknownOp), NewDNA[SignalType](d.Source)); err == nil {
+		t.Errorf("Want error for an unknown op, got none")
+	}
+
+	staleTable := fmt.Sprintf(`{"op":"OR","kind":"PURE","table":[%s0]}`, strings.Repeat("0,", TableSize-1))
+	if err := json.Unmarshal([]byte(staleTable), &Neuron[SignalType]{}); err == nil || !strings.Contains(err.Error(), "table") {
+		t.Errorf("Want error for a table on a PURE neuron, got %v", err)
+	}
+
+	nullNeuron := `{"version":1,"neurons":{"0":null},"synapses":[]}`
+	if err := json.Unmarshal([]byte(nullNeuron), NewDNA[SignalType](d.Source)); err == nil || !strings.Contains(err.Error(), "null") {
+		t.Errorf("Want error for a null neuron, got %v", err)
+	}
+}
+
+func TestPlaygroundJSON(t *testing.T) {
+	config := createTestPlayConfig()
+	config.Mconf.AdaptOps = 0.5
+	p := NewPlayground[SignalType](config)
+	p.InitDNA()
+	scores := make([]BrainScore, config.NumVariants)
+	for id := range scores {
+		scores[id] = BrainScore{id: id, score: ScoreType(id)}
+	}
+	p.Evolve(scores)
+
+	data, err := json.Marshal(p)
+	if err != nil {
+		t.Fatalf("Got error %v", err)
+	}
+	loaded := NewPlayground[SignalType](config)
+	if err := json.Unmarshal(data, loaded); err != nil {
+		t.Fatalf("Got error %v", err)
+	}
+
+	if got, want := loaded.source.Synapses, p.source.Synapses; !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %+v, want %+v", got, want)
+	}
+	if got, want := len(loaded.codes), len(p.codes); got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+	for id, dna := range p.codes {
+		if got, want := loaded.codes[id].PrettyPrint(), dna.PrettyPrint(); got != want {
+			t.Errorf("DNA %d: got %v, want %v", id, got, want)
+		}
+		if loaded.codes[id].Source != loaded.source {
+			t.Errorf("DNA %d should point to the loaded conglomerate", id)
+		}
+	}
+	if got, want := len(loaded.species), len(p.species); got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+	for id, species := range p.species {
+		if got, want := loaded.species[id].repFitness, species.repFitness; got != want {
+			t.Errorf("Species %d: got %v, want %v", id, got, want)
+		}
+	}
+	if got, want := loaded.SpeciesMembers(), p.SpeciesMembers(); len(got) != len(want) || got[0].Weight != want[0].Weight {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+	if got, want := loaded.generations, 1; got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+	if !reflect.DeepEqual(loaded.opChoices, p.opChoices) || !reflect.DeepEqual(loaded.opWeights, p.opWeights) {
+		t.Errorf("Got op weights %v %v, want %v %v", loaded.opChoices, loaded.opWeights, p.opChoices, p.opWeights)
+	}
+
+	// Evolution carries on from the loaded state.
+	loaded.Evolve(scores)
+}
@@ -0,0 +1,310 @@
+package neuron
+
+import (
+	"fmt"
+	"sort"
+)
+
+// CarryPolicy decides what a brain remembers from one Fire to the next. Games
+// with many turns, like HealthChecker, fire the same brain once per turn.
+type CarryPolicy int
+
+const (
+	// CARRY_ALL keeps the leftover signals and the memory of stateful neurons,
+	// which is the default.
+	CARRY_ALL CarryPolicy = iota
+	// CARRY_STATE only keeps the memory of stateful neurons, so every Fire
+	// starts without any signals from the last one.
+	CARRY_STATE
+	// CARRY_PENDING only keeps the leftover signals.
+	CARRY_PENDING
+	// CARRY_NOTHING starts every Fire as if the brain had just been grown.
+	CARRY_NOTHING
+)
+
+func (c CarryPolicy) String() string {
+	switch c {
+	case CARRY_ALL:
+		return "CARRY_ALL"
+	case CARRY_STATE:
+		return "CARRY_STATE"
+	case CARRY_PENDING:
+		return "CARRY_PENDING"
+	case CARRY_NOTHING:
+		return "CARRY_NOTHING"
+	}
+	return fmt.Sprintf("CarryPolicy(%d)", int(c))
+}
+
+// keepsPending is true if leftover signals carry over. Neurons that are still
+// resting stay resting along with them.
+func (c CarryPolicy) keepsPending() bool {
+	return c == CARRY_ALL || c == CARRY_PENDING
+}
+
+// keepsState is true if the memory of stateful neurons carries over.
+func (c CarryPolicy) keepsState() bool {
+	return c == CARRY_ALL || c == CARRY_STATE
+}
+
+// Carrier is an optional interface for games that need a different
+// CarryPolicy than the one in the RunnerConfig.
+type Carrier interface {
+	CarryPolicy() CarryPolicy
+}
+
+// BrainSnapshot is a copy of everything a brain remembers between fires. It's
+// keyed by neuron and synapse IDs, so a snapshot of a Brain can be restored
+// into a CompiledBrain of the same DNA and the other way around.
+type BrainSnapshot[S Signal] struct {
+	// pending are the leftover signals of each neuron, in input slot order.
+	pending map[IDType][]pendingSignal[S]
+	state   map[IDType]S
+	// rest is the number of steps each resting neuron has left.
+	rest map[IDType]int
+}
+
+// NumPending returns the number of leftover signals in the snapshot.
+func (s *BrainSnapshot[S]) NumPending() int {
+	numPending := 0
+	for _, pending := range s.pending {
+		numPending += len(pending)
+	}
+	return numPending
+}
+
+// SetCarryPolicy changes what the brain keeps from one Fire to the next.
+func (b *Brain[S]) SetCarryPolicy(policy CarryPolicy) {
+	b.carry = policy
+}
+
+// Reset clears the leftover signals, the memory of stateful neurons and any
+// resting, as if the brain had never fired. The Firings count is kept.
+func (b *Brain[S]) Reset() {
+	b.pendingSignals = make(map[IDType][]pendingSignal[S], len(b.dna.Neurons))
+	b.outputSignals = make([]brainOutput[S], b.dna.Source.NeuronIDs[MOTOR].Length())
+	b.state = make(map[IDType]S)
+	b.restUntil = make(map[IDType]int)
+}
+
+// carryOver drops whatever the CarryPolicy doesn't keep for the next Fire.
+func (b *Brain[S]) carryOver() {
+	if !b.carry.keepsPending() {
+		b.pendingSignals = make(map[IDType][]pendingSignal[S], len(b.dna.Neurons))
+		b.restUntil = make(map[IDType]int)
+	}
+	if !b.carry.keepsState() {
+		b.state = make(map[IDType]S)
+	}
+}
+
+// Snapshot copies everything the brain remembers between fires.
+func (b *Brain[S]) Snapshot() *BrainSnapshot[S] {
+	snap := &BrainSnapshot[S]{
+		pending: make(map[IDType][]pendingSignal[S], len(b.pendingSignals)),
+		state:   make(map[IDType]S, len(b.state)),
+		rest:    make(map[IDType]int),
+	}
+	for neuronID, pending := range b.pendingSignals {
+		snap.pending[neuronID] = append([]pendingSignal[S]{}, pending...)
+	}
+	for neuronID, state := range b.state {
+		snap.state[neuronID] = state
+	}
+	for neuronID, restUntil := range b.restUntil {
+		if restUntil > b.steps {
+			snap.rest[neuronID] = restUntil - b.steps
+		}
+	}
+	return snap
+}
+
+// Restore puts the brain back the way it was when the snapshot was taken. It
+// returns an error without changing anything if the snapshot refers to
+// neurons or input slots that the brain doesn't have.
+func (b *Brain[S]) Restore(snap *BrainSnapshot[S]) error {
+	for neuronID, pending := range snap.pending {
+		if _, ok := b.dna.Neurons[neuronID]; !ok {
+			return fmt.Errorf("snapshot has signals for missing neuron %d", neuronID)
+		}
+		for _, p := range pending {
+			if !b.hasSlot(neuronID, p.slot) {
+				return fmt.Errorf("snapshot has signals for missing slot %d of neuron %d", p.slot, neuronID)
+			}
+		}
+	}
+	if err := checkSnapshotNeurons(snap, b.dna.Neurons); err != nil {
+		return err
+	}
+
+	b.Reset()
+	for neuronID, pending := range snap.pending {
+		b.pendingSignals[neuronID] = append([]pendingSignal[S]{}, pending...)
+	}
+	for neuronID, state := range snap.state {
+		b.state[neuronID] = state
+	}
+	for neuronID, rest := range snap.rest {
+		b.restUntil[neuronID] = b.steps + rest
+	}
+	return nil
+}
+
+func (b *Brain[S]) hasSlot(neuronID, slot IDType) bool {
+	if slot == SenseSlot {
+		return b.dna.Source.NeuronIDs[SENSE].HasID(neuronID)
+	}
+	syn, ok := b.dna.Synpases.idMap[slot]
+	return ok && syn.dst == neuronID
+}
+
+// checkSnapshotNeurons makes sure the state and resting neurons of the
+// snapshot are all in the DNA.
+func checkSnapshotNeurons[S Signal](snap *BrainSnapshot[S], neurons map[IDType]*Neuron[S]) error {
+	for neuronID := range snap.state {
+		if _, ok := neurons[neuronID]; !ok {
+			return fmt.Errorf("snapshot has state for missing neuron %d", neuronID)
+		}
+	}
+	for neuronID := range snap.rest {
+		if _, ok := neurons[neuronID]; !ok {
+			return fmt.Errorf("snapshot has missing neuron %d resting", neuronID)
+		}
+	}
+	return nil
+}
+
+// SetCarryPolicy works the same as Brain.SetCarryPolicy.
+func (b *CompiledBrain[S]) SetCarryPolicy(policy CarryPolicy) {
+	b.carry = policy
+}
+
+// Reset works the same as Brain.Reset.
+func (b *CompiledBrain[S]) Reset() {
+	b.clearPending()
+	for motorIndex := range b.outputs {
+		b.outputs[motorIndex] = b.outputs[motorIndex][:0]
+		b.terminated[motorIndex] = false
+	}
+	for i := range b.state {
+		b.state[i] = 0
+		b.restUntil[i] = 0
+	}
+}
+
+// carryOver works the same as Brain.carryOver.
+func (b *CompiledBrain[S]) carryOver() {
+	if !b.carry.keepsPending() {
+		b.clearPending()
+		for i := range b.restUntil {
+			b.restUntil[i] = 0
+		}
+	}
+	if !b.carry.keepsState() {
+		for i := range b.state {
+			b.state[i] = 0
+		}
+	}
+}
+
+// clearPending empties every slot, keeping the buffers for later.
+func (b *CompiledBrain[S]) clearPending() {
+	p := b.plan
+	for _, i := range b.active {
+		for slot := p.slotStart[i]; slot < p.slotStart[i+1]; slot++ {
+			b.pending[slot] = b.pending[slot][:0]
+		}
+		b.numPending[i] = 0
+		b.numFilled[i] = 0
+		b.isActive[i] = false
+	}
+	b.active = b.active[:0]
+}
+
+// Snapshot works the same as Brain.Snapshot.
+func (b *CompiledBrain[S]) Snapshot() *BrainSnapshot[S] {
+	p := b.plan
+	snap := &BrainSnapshot[S]{
+		pending: make(map[IDType][]pendingSignal[S], len(b.active)),
+		state:   make(map[IDType]S),
+		rest:    make(map[IDType]int),
+	}
+	for _, i := range b.active {
+		pending := make([]pendingSignal[S], 0, b.numPending[i])
+		for slot := p.slotStart[i]; slot < p.slotStart[i+1]; slot++ {
+			for _, sig := range b.pending[slot] {
+				pending = append(pending, pendingSignal[S]{slot: p.slotID[slot], sig: sig})
+			}
+		}
+		snap.pending[p.ids[i]] = pending
+	}
+	for i, id := range p.ids {
+		if p.stateful[i] {
+			snap.state[id] = b.state[i]
+		}
+		if b.restUntil[i] > b.steps {
+			snap.rest[id] = b.restUntil[i] - b.steps
+		}
+	}
+	return snap
+}
+
+// Restore works the same as Brain.Restore.
+func (b *CompiledBrain[S]) Restore(snap *BrainSnapshot[S]) error {
+	p := b.plan
+	neurons := make(map[IDType]*Neuron[S], len(p.ids))
+	for i, id := range p.ids {
+		neurons[id] = p.neurons[i]
+	}
+	if err := checkSnapshotNeurons(snap, neurons); err != nil {
+		return err
+	}
+
+	slots := make(map[IDType][]int, len(snap.pending))
+	for neuronID, pending := range snap.pending {
+		i, ok := p.index(neuronID)
+		if !ok {
+			return fmt.Errorf("snapshot has signals for missing neuron %d", neuronID)
+		}
+		for _, sig := range pending {
+			slot := p.findSlot(i, sig.slot)
+			if slot < 0 {
+				return fmt.Errorf("snapshot has signals for missing slot %d of neuron %d", sig.slot, neuronID)
+			}
+			slots[neuronID] = append(slots[neuronID], slot)
+		}
+	}
+
+	b.Reset()
+	for neuronID, pending := range snap.pending {
+		for j, sig := range pending {
+			b.deliver(slots[neuronID][j], sig.sig)
+		}
+	}
+	for neuronID, state := range snap.state {
+		i, _ := p.index(neuronID)
+		b.state[i] = state
+	}
+	for neuronID, rest := range snap.rest {
+		i, _ := p.index(neuronID)
+		b.restUntil[i] = b.steps + rest
+	}
+	return nil
+}
+
+// index returns the dense index of the neuron, if it's in the plan.
+func (p *Plan[S]) index(neuronID IDType) (int, bool) {
+	i := sort.SearchInts(p.ids, neuronID)
+	return i, i < len(p.ids) && p.ids[i] == neuronID
+}
+
+// findSlot returns the global slot of the neuron's input slot, or -1 if it
+// doesn't have one.
+func (p *Plan[S]) findSlot(i int, slotID IDType) int {
+	for slot := p.slotStart[i]; slot < p.slotStart[i+1]; slot++ {
+		if p.slotID[slot] == slotID {
+			return slot
+		}
+	}
+	return -1
+}
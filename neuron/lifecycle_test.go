@@ -0,0 +1,134 @@
+package neuron
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCarryPolicy(t *testing.T) {
+	for _, test := range []struct {
+		policy      CarryPolicy
+		wantPending int
+		wantState   SignalType
+	}{
+		{CARRY_ALL, 1, 5},
+		{CARRY_STATE, 0, 5},
+		{CARRY_PENDING, 1, 0},
+		{CARRY_NOTHING, 0, 0},
+	} {
+		d := SimpleTestDNA()
+		d.Neurons[2].Kind = ACCUMULATOR
+		brains := map[string]interface {
+			SetCarryPolicy(CarryPolicy)
+			FireResult([][]SignalType) FireResult[SignalType]
+			Snapshot() *BrainSnapshot[SignalType]
+		}{
+			"Brain":         Flourish(d),
+			"CompiledBrain": Compile(d).NewBrain(),
+		}
+		for name, b := range brains {
+			b.SetCarryPolicy(test.policy)
+			// The last 0 into the motor never gets a partner, so it's left over.
+			result := b.FireResult([][]SignalType{{1, 2}, {3}})
+			if got, want := result.Pending, 1; got != want {
+				t.Errorf("%s %v: got %v, want %v", name, test.policy, got, want)
+			}
+
+			snap := b.Snapshot()
+			if got, want := snap.NumPending(), test.wantPending; got != want {
+				t.Errorf("%s %v: got %v, want %v", name, test.policy, got, want)
+			}
+			// The accumulator has added up 1|3 and 2|0 so far.
+			if got, want := snap.state[2], test.wantState; got != want {
+				t.Errorf("%s %v: got %v, want %v", name, test.policy, got, want)
+			}
+		}
+	}
+}
+
+func TestBrainReset(t *testing.T) {
+	d := SimpleTestDNA()
+	d.Neurons[2].Kind = ACCUMULATOR
+	b := Flourish(d)
+	b.Fire([][]SignalType{{1, 2}, {3}})
+	b.Reset()
+
+	snap := b.Snapshot()
+	if got, want := snap.NumPending(), 0; got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+	if got, want := len(snap.state), 0; got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+	if got, want := b.Firings(), 7; got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+}
+
+func TestBrainSnapshotRestore(t *testing.T) {
+	seed := time.Now().UnixNano()
+	rnd := rand.New(rand.NewSource(seed))
+	for trial := 0; trial < 30; trial++ {
+		dna := randomTestDNA(rnd)
+		numInputs := dna.Source.NeuronIDs[SENSE].Length()
+		brain := Flourish(dna)
+		for fire := 0; fire < 3; fire++ {
+			brain.Fire(randomTestInputs(rnd, numInputs))
+		}
+
+		snap := brain.Snapshot()
+		inputs := randomTestInputs(rnd, numInputs)
+		want := brain.FireResult(inputs)
+		brain.Fire(randomTestInputs(rnd, numInputs))
+
+		if err := brain.Restore(snap); err != nil {
+			t.Fatalf("Seed %d, trial %d: got error %v", seed, trial, err)
+		}
+		if got := brain.FireResult(inputs); !reflect.DeepEqual(got, want) {
+			t.Errorf("Seed %d, trial %d: got %v, want %v for:\n%s", seed, trial, got, want, dna.PrettyPrint())
+		}
+
+		// Snapshots are keyed by ID, so they work for compiled brains too.
+		compiled := Compile(dna).NewBrain()
+		compiled.Fire(randomTestInputs(rnd, numInputs))
+		if err := compiled.Restore(snap); err != nil {
+			t.Fatalf("Seed %d, trial %d: got error %v", seed, trial, err)
+		}
+		if got := compiled.FireResult(inputs); !reflect.DeepEqual(got, want) {
+			t.Errorf("Seed %d, trial %d: got %v, want %v for:\n%s", seed, trial, got, want, dna.PrettyPrint())
+		}
+		if err := compiled.Restore(snap); err != nil {
+			t.Fatalf("Seed %d, trial %d: got error %v", seed, trial, err)
+		}
+		if got, want := compiled.Snapshot().NumPending(), snap.NumPending(); got != want {
+			t.Errorf("Seed %d, trial %d: got %v, want %v", seed, trial, got, want)
+		}
+	}
+}
+
+func TestBrainRestoreErrors(t *testing.T) {
+	d := SimpleTestDNA()
+	for _, snap := range []*BrainSnapshot[SignalType]{
+		{pending: map[IDType][]pendingSignal[SignalType]{9: {{slot: 0, sig: 1}}}},
+		{pending: map[IDType][]pendingSignal[SignalType]{2: {{slot: 5, sig: 1}}}},
+		{pending: map[IDType][]pendingSignal[SignalType]{2: {{slot: SenseSlot, sig: 1}}}},
+		{state: map[IDType]SignalType{9: 1}},
+		{rest: map[IDType]int{9: 1}},
+	} {
+		brain := Flourish(d)
+		brain.Fire([][]SignalType{{1, 2}, {3}})
+		if err := brain.Restore(snap); err == nil {
+			t.Errorf("Want error restoring %+v, got none", snap)
+		}
+		// Nothing changes when the snapshot can't be restored.
+		if got, want := brain.Snapshot().NumPending(), 1; got != want {
+			t.Errorf("Got %v, want %v", got, want)
+		}
+
+		if err := Compile(d).NewBrain().Restore(snap); err == nil {
+			t.Errorf("Want error restoring %+v, got none", snap)
+		}
+	}
+}
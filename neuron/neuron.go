@@ -1,21 +1,96 @@
 package neuron
 
 import (
+	"fmt"
 	"log"
 	"math"
+	"sync"
 )
 
-// SignalType is the value held in a neuron. "byte" is an alias for uint8.
+// Signal is the set of types that can be held in a neuron. The whole package
+// is generic over the signal, so an environment can pick the width it needs.
+type Signal interface {
+	uint8 | uint16 | uint32 | Fixed
+}
+
+// SignalType is the default signal, for environments that fit in a byte.
+// "byte" is an alias for uint8.
 type SignalType = uint8
 
+// Fixed is an unsigned fixed point signal with FixedFracBits of fraction.
+type Fixed uint32
+
+// FixedFracBits is the number of bits after the binary point in a Fixed.
+const FixedFracBits = 16
+
+// FixedFromFloat converts a float to the nearest Fixed, saturating at the
+// bounds of the type.
+func FixedFromFloat(f float64) Fixed {
+	scaled := math.Round(f * (1 << FixedFracBits))
+	if scaled <= 0 {
+		return 0
+	}
+	if scaled >= math.MaxUint32 {
+		return math.MaxUint32
+	}
+	return Fixed(scaled)
+}
+
+// Float converts the fixed point value back to a float.
+func (f Fixed) Float() float64 {
+	return float64(f) / (1 << FixedFracBits)
+}
+
+// Word is wide enough to hold any Signal, so operators can be written once
+// and applied to every signal type.
+type Word = uint64
+
+// Width describes how a Signal type is represented in a Word.
+type Width struct {
+	Bits     uint
+	FracBits uint
+}
+
+// WidthOf returns the Width of the signal type.
+func WidthOf[S Signal]() Width {
+	var zero S
+	switch any(zero).(type) {
+	case uint8:
+		return Width{Bits: 8}
+	case uint16:
+		return Width{Bits: 16}
+	case Fixed:
+		return Width{Bits: 32, FracBits: FixedFracBits}
+	default:
+		return Width{Bits: 32}
+	}
+}
+
+// Max is the highest value that fits in the width.
+func (w Width) Max() Word {
+	return (1 << w.Bits) - 1
+}
+
+// One is the representation of the number 1, which is only different from a
+// plain 1 for fixed point signals.
+func (w Width) One() Word {
+	return 1 << w.FracBits
+}
+
+// Mask drops any bits that don't fit in the width.
+func (w Width) Mask(x Word) Word {
+	return x & w.Max()
+}
+
 // MaxSignal returns the highest number for the signal type, to
 // avoid having to change math.Max___ everywhere in the code.
-func MaxSignal() SignalType {
-	return math.MaxUint8
+func MaxSignal[S Signal]() S {
+	return S(WidthOf[S]().Max())
 }
 
-// OperatorType enum for different operations that neurons can perform on
-// signal values. The operations must be associative and commutative.
+// OperatorType identifies an operation that neurons can perform on signal
+// values. Operators are looked up in a registry, so callers can add their own
+// with RegisterOperator alongside the built in ones below.
 type OperatorType int
 
 const (
@@ -32,74 +107,349 @@ const (
 	MIN
 	TRUTH
 	FALSIFY
+	SUBTRACT
+	DIVIDE
+	MOD
+	SHIFT_LEFT
+	SHIFT_RIGHT
+	COMPARE
+	SAT_ADD
+	SAT_MUL
+	AVERAGE
+	ABS_DIFF
+	MUL_HIGH
 )
 
-// NumOps is the total number of OperatorTypes, used to pick one randomly.
-const NumOps = 13
-
-// Operate performs the operation on a series of inputs.
-func (op OperatorType) Operate(sigs []SignalType) SignalType {
-	switch op {
-	case AND:
-		return applyToAll(sigs, func(a, b SignalType) SignalType { return a & b })
-	case NAND:
-		return ^AND.Operate(sigs)
-	case OR:
-		return applyToAll(sigs, func(a, b SignalType) SignalType { return a | b })
-	case NOR:
-		return ^OR.Operate(sigs)
-	case XOR:
-		return applyToAll(sigs, func(a, b SignalType) SignalType { return a ^ b })
-	case IFF:
-		return ^XOR.Operate(sigs)
-	case ADD:
-		return applyToAll(sigs, func(a, b SignalType) SignalType { return a + b })
-	case MULTIPLY:
-		return applyToAll(sigs, func(a, b SignalType) SignalType { return a * b })
-	case GCF:
-		return applyToAll(sigs, func(a, b SignalType) SignalType {
-			for b != 0 {
-				tmp := b
-				b = a % b
-				a = tmp
-			}
+// Operator describes how an OperatorType combines a series of inputs. The
+// inputs are folded together in the order of their input slots, starting from
+// the Identity, or from the first input for operators without one. Operators
+// work on Words along with the Width of the signal, so the same definition
+// covers every Signal type.
+type Operator struct {
+	// Name is unique across the registry, and is used to look up the operator.
+	Name string
+
+	// Combine folds the next signal into the running result. The result is
+	// masked to the width after every step.
+	Combine func(acc, sig Word, w Width) Word
+
+	// Identity returns the element that leaves any signal unchanged when
+	// combined with it, which is also the result when there are no inputs.
+	// Operators like SUBTRACT don't have one, so it can be nil.
+	Identity func(w Width) Word
+
+	// Commutative is true when the order of the inputs doesn't matter.
+	Commutative bool
+	// Associative is true when Combine gives the same result regardless of how
+	// the inputs are grouped. Use IsAssociative to check a particular Width.
+	Associative bool
+	// Truncates is true when Combine rounds off fraction bits, so grouping the
+	// inputs differently can round differently for fixed point signals.
+	Truncates bool
+
+	// Invert flips all the bits of the result after folding, such as NAND.
+	Invert bool
+
+	// MinInputs is the fewest inputs the operator accepts. Operating on fewer
+	// inputs than that returns the Identity, or zero without one.
+	MinInputs int
+	// MaxInputs caps the number of inputs, and any extras are ignored. A
+	// neuron's seed always counts as one of them. A value of 0 means there's no
+	// limit.
+	MaxInputs int
+}
+
+// IsAssociative is true when the operator is associative for signals of the
+// width, which an operator that Truncates isn't once there are fraction bits.
+func (o Operator) IsAssociative(w Width) bool {
+	return o.Associative && !(o.Truncates && w.FracBits > 0)
+}
+
+// operatorRegistry holds every known operator, where the OperatorType is the
+// index into the ops slice.
+type operatorRegistry struct {
+	mu    sync.RWMutex
+	ops   []Operator
+	names map[string]OperatorType
+}
+
+// saturate clamps the value to the highest signal instead of wrapping around.
+func saturate(x Word, w Width) Word {
+	if x > w.Max() {
+		return w.Max()
+	}
+	return x
+}
+
+// ZeroIdentity is the identity of operators like OR and ADD.
+func ZeroIdentity(w Width) Word {
+	return 0
+}
+
+// MaxIdentity is the identity of operators like AND and MIN.
+func MaxIdentity(w Width) Word {
+	return w.Max()
+}
+
+// OneIdentity is the identity of MULTIPLY.
+func OneIdentity(w Width) Word {
+	return w.One()
+}
+
+var registry = newOperatorRegistry([]Operator{
+	AND: {Name: "AND", Identity: MaxIdentity, Commutative: true, Associative: true, Combine: func(a, b Word, w Width) Word {
+		return a & b
+	}},
+	NAND: {Name: "NAND", Identity: MaxIdentity, Commutative: true, Associative: true, Invert: true, Combine: func(a, b Word, w Width) Word {
+		return a & b
+	}},
+	OR: {Name: "OR", Identity: ZeroIdentity, Commutative: true, Associative: true, Combine: func(a, b Word, w Width) Word {
+		return a | b
+	}},
+	NOR: {Name: "NOR", Identity: ZeroIdentity, Commutative: true, Associative: true, Invert: true, Combine: func(a, b Word, w Width) Word {
+		return a | b
+	}},
+	XOR: {Name: "XOR", Identity: ZeroIdentity, Commutative: true, Associative: true, Combine: func(a, b Word, w Width) Word {
+		return a ^ b
+	}},
+	IFF: {Name: "IFF", Identity: ZeroIdentity, Commutative: true, Associative: true, Invert: true, Combine: func(a, b Word, w Width) Word {
+		return a ^ b
+	}},
+	ADD: {Name: "ADD", Identity: ZeroIdentity, Commutative: true, Associative: true, Combine: func(a, b Word, w Width) Word {
+		return a + b
+	}},
+	MULTIPLY: {Name: "MULTIPLY", Identity: OneIdentity, Commutative: true, Associative: true, Truncates: true, Combine: func(a, b Word, w Width) Word {
+		// Fixed point products have twice the fraction bits, so shift them back.
+		return (a * b) >> w.FracBits
+	}},
+	GCF: {Name: "GCF", Identity: ZeroIdentity, Commutative: true, Associative: true, Combine: func(a, b Word, w Width) Word {
+		for b != 0 {
+			tmp := b
+			b = a % b
+			a = tmp
+		}
+		return a
+	}},
+	MAX: {Name: "MAX", Identity: ZeroIdentity, Commutative: true, Associative: true, Combine: func(a, b Word, w Width) Word {
+		if a > b {
 			return a
-		})
-	case MIN:
-		return applyToAll(sigs, func(a, b SignalType) SignalType {
-			if a < b {
-				return a
-			}
-			return b
-		})
-	case MAX:
-		return applyToAll(sigs, func(a, b SignalType) SignalType {
-			if a > b {
-				return a
-			}
-			return b
-		})
-	case TRUTH:
-		return MaxSignal()
-	case FALSIFY:
+		}
+		return b
+	}},
+	MIN: {Name: "MIN", Identity: MaxIdentity, Commutative: true, Associative: true, Combine: func(a, b Word, w Width) Word {
+		if a < b {
+			return a
+		}
+		return b
+	}},
+	TRUTH: {Name: "TRUTH", Identity: MaxIdentity, Commutative: true, Associative: true, Combine: func(a, b Word, w Width) Word {
+		return w.Max()
+	}},
+	FALSIFY: {Name: "FALSIFY", Identity: ZeroIdentity, Commutative: true, Associative: true, Combine: func(a, b Word, w Width) Word {
 		return 0
-	default:
-		log.Fatalf("Unhandled operator: %d", op)
+	}},
+	SUBTRACT: {Name: "SUBTRACT", Combine: func(a, b Word, w Width) Word {
+		return a - b
+	}},
+	DIVIDE: {Name: "DIVIDE", Combine: func(a, b Word, w Width) Word {
+		// Dividing by zero saturates instead of panicking.
+		if b == 0 {
+			return w.Max()
+		}
+		return (a << w.FracBits) / b
+	}},
+	MOD: {Name: "MOD", Combine: func(a, b Word, w Width) Word {
+		if b == 0 {
+			return a
+		}
+		return a % b
+	}},
+	SHIFT_LEFT: {Name: "SHIFT_LEFT", Combine: func(a, b Word, w Width) Word {
+		// Only the whole number part of the signal is used as the shift amount.
+		return a << (b >> w.FracBits)
+	}},
+	SHIFT_RIGHT: {Name: "SHIFT_RIGHT", Combine: func(a, b Word, w Width) Word {
+		return a >> (b >> w.FracBits)
+	}},
+	COMPARE: {Name: "COMPARE", MinInputs: 2, MaxInputs: 2, Combine: func(a, b Word, w Width) Word {
+		// Like TRUTH and FALSIFY, the result is either all or nothing.
+		if a > b {
+			return w.Max()
+		}
 		return 0
+	}},
+	SAT_ADD: {Name: "SAT_ADD", Identity: ZeroIdentity, Commutative: true, Associative: true, Combine: func(a, b Word, w Width) Word {
+		return saturate(a+b, w)
+	}},
+	SAT_MUL: {Name: "SAT_MUL", Identity: OneIdentity, Commutative: true, Associative: true, Truncates: true, Combine: func(a, b Word, w Width) Word {
+		return saturate((a*b)>>w.FracBits, w)
+	}},
+	// The rest are only commutative for exactly two inputs, so they're limited
+	// to two. A seed takes one of the two, and the Word is wide enough that the
+	// pair can't overflow.
+	AVERAGE: {Name: "AVERAGE", MaxInputs: 2, Commutative: true, Combine: func(a, b Word, w Width) Word {
+		return (a + b) / 2
+	}},
+	ABS_DIFF: {Name: "ABS_DIFF", MaxInputs: 2, Commutative: true, Combine: func(a, b Word, w Width) Word {
+		if a > b {
+			return a - b
+		}
+		return b - a
+	}},
+	MUL_HIGH: {Name: "MUL_HIGH", MaxInputs: 2, Commutative: true, Combine: func(a, b Word, w Width) Word {
+		// The upper half of the double width product.
+		return (a * b) >> w.Bits
+	}},
+})
+
+func newOperatorRegistry(builtins []Operator) *operatorRegistry {
+	r := &operatorRegistry{
+		ops:   make([]Operator, 0, len(builtins)),
+		names: make(map[string]OperatorType, len(builtins)),
 	}
+	for _, op := range builtins {
+		if _, err := r.register(op); err != nil {
+			log.Fatalf("Invalid builtin operator: %v", err)
+		}
+	}
+	return r
 }
 
-func applyToAll(sigs []SignalType, operateFn func(a, b SignalType) SignalType) SignalType {
-	x := sigs[0]
-	for i := 1; i < len(sigs); i++ {
-		x = operateFn(x, sigs[i])
+func (r *operatorRegistry) register(op Operator) (OperatorType, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if op.Name == "" {
+		return 0, fmt.Errorf("operator must have a name")
 	}
-	return x
+	if op.Combine == nil {
+		return 0, fmt.Errorf("operator %s must have a combine function", op.Name)
+	}
+	if op.MaxInputs != 0 && op.MaxInputs < op.MinInputs {
+		return 0, fmt.Errorf("operator %s has max inputs %d below min inputs %d", op.Name, op.MaxInputs, op.MinInputs)
+	}
+	if _, exists := r.names[op.Name]; exists {
+		return 0, fmt.Errorf("operator %s is already registered", op.Name)
+	}
+
+	opType := OperatorType(len(r.ops))
+	r.ops = append(r.ops, op)
+	r.names[op.Name] = opType
+	return opType, nil
+}
+
+// unregister removes the last operator registered, which tests use to clean
+// up after themselves. Only the last one can be removed, since the
+// OperatorTypes of the others are their positions in the registry.
+func (r *operatorRegistry) unregister(opType OperatorType) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if int(opType) != len(r.ops)-1 {
+		log.Fatalf("Only the last operator can be unregistered, not %d", opType)
+	}
+	delete(r.names, r.ops[opType].Name)
+	r.ops = r.ops[:opType]
+}
+
+func (r *operatorRegistry) get(opType OperatorType) Operator {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if opType < 0 || int(opType) >= len(r.ops) {
+		log.Fatalf("Unhandled operator: %d", opType)
+	}
+	return r.ops[opType]
+}
+
+// RegisterOperator adds a new operator to the registry and returns the
+// OperatorType that neurons can use to reference it.
+func RegisterOperator(op Operator) (OperatorType, error) {
+	return registry.register(op)
+}
+
+// LookupOperator returns the OperatorType registered under the name.
+func LookupOperator(name string) (OperatorType, bool) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	opType, ok := registry.names[name]
+	return opType, ok
+}
+
+// RegisteredOps returns every OperatorType in the registry, in the order they
+// were registered.
+func RegisteredOps() []OperatorType {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	ops := make([]OperatorType, len(registry.ops))
+	for i := range registry.ops {
+		ops[i] = OperatorType(i)
+	}
+	return ops
+}
+
+// NumOps is the total number of registered OperatorTypes, used to pick one
+// randomly.
+func NumOps() int {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	return len(registry.ops)
+}
+
+// Operator returns the registered definition of this OperatorType.
+func (op OperatorType) Operator() Operator {
+	return registry.get(op)
+}
+
+// String returns the registered name of the operator.
+func (op OperatorType) String() string {
+	return registry.get(op).Name
+}
+
+// Operate performs the operation on a series of inputs, which are given in
+// the order of their input slots.
+func Operate[S Signal](op OperatorType, sigs []S) S {
+	def := registry.get(op)
+	w := WidthOf[S]()
+	if len(sigs) < def.MinInputs {
+		return S(w.Mask(def.identity(w)))
+	}
+	if def.MaxInputs != 0 && len(sigs) > def.MaxInputs {
+		sigs = sigs[:def.MaxInputs]
+	}
+
+	// Without an identity, the fold starts from the first input.
+	var x Word
+	if def.Identity != nil {
+		x = w.Mask(def.Identity(w))
+	} else if len(sigs) > 0 {
+		x = w.Mask(Word(sigs[0]))
+		sigs = sigs[1:]
+	}
+	for _, sig := range sigs {
+		x = w.Mask(def.Combine(x, Word(sig), w))
+	}
+	if def.Invert {
+		x = ^x
+	}
+	return S(w.Mask(x))
+}
+
+// identity is the result of operating on too few inputs, which is zero for
+// operators without an Identity.
+func (op Operator) identity(w Width) Word {
+	if op.Identity == nil {
+		return 0
+	}
+	return op.Identity(w)
 }
 
 // InterpretOp converts an int to its corresponding OperatorType.
 func InterpretOp(x int) OperatorType {
-	return [...]OperatorType{AND, NAND, OR, NOR, XOR, IFF, ADD, MULTIPLY, GCF, MAX, MIN, TRUTH, FALSIFY}[x]
+	if x < 0 || x >= NumOps() {
+		log.Fatalf("Unregistered operator: %d", x)
+	}
+	return OperatorType(x)
 }
 
 // IDType standardizes the type of IDs used everywhere, so they can
@@ -129,61 +479,233 @@ const (
 // NeuronTypes holds all possible enum values for looping.
 var NeuronTypes = []NeuronType{SENSE, INTER, MOTOR}
 
+func (t NeuronType) String() string {
+	return [...]string{"SENSE", "INTER", "MOTOR"}[t]
+}
+
+// NeuronKind is an enum for what a neuron does with its inputs. The stateful
+// kinds carry state from one firing to the next, which lasts until the brain
+// is reset.
+type NeuronKind int
+
+const (
+	// PURE neurons output the result of their operation.
+	PURE NeuronKind = iota
+	// LATCH neurons hold on to their first non-zero result and output it from
+	// then on.
+	LATCH
+	// ACCUMULATOR neurons output the running total of their results.
+	ACCUMULATOR
+	// COUNTER neurons output the number of times their result was non-zero.
+	COUNTER
+	// MEMORY neurons output the result of their previous firing, and remember
+	// the current result for the next one.
+	MEMORY
+	// TABLE neurons look up their inputs in a Table instead of using their
+	// operation, so they can represent any function of a single input.
+	TABLE
+)
+
+// NeuronKinds holds all possible enum values for looping.
+var NeuronKinds = []NeuronKind{PURE, LATCH, ACCUMULATOR, COUNTER, MEMORY, TABLE}
+
+func (k NeuronKind) String() string {
+	return [...]string{"PURE", "LATCH", "ACCUMULATOR", "COUNTER", "MEMORY", "TABLE"}[k]
+}
+
+// DefaultThreshold is the number of signals new neurons need before firing.
+const DefaultThreshold = 2
+
+// AllInputs is a Threshold for neurons that wait until every one of their
+// input slots has delivered a signal.
+const AllInputs = -1
+
+// MaxThreshold and MaxRefractory bound the values mutations can pick.
+const (
+	MaxThreshold  = 4
+	MaxRefractory = 3
+)
+
+// TableSize is the number of entries in a lookup table, which covers every
+// value of a byte.
+const TableSize = 256
+
 // Neuron is the base struct of this entire project. It performs a simple
 // operation on its inputs and gives one output.
-type Neuron struct {
-	Op OperatorType
+type Neuron[S Signal] struct {
+	Op   OperatorType
+	Kind NeuronKind
 
 	HasSeed bool
-	Seed    SignalType
+	Seed    S
+
+	// Table has TableSize entries, and is only used by TABLE neurons.
+	Table []S
+
+	// Threshold is the number of signals, including the seed, the neuron needs
+	// before firing. It can also be AllInputs.
+	Threshold int
+	// Refractory is the number of steps the neuron rests after firing, while
+	// its signals keep piling up.
+	Refractory int
 }
 
 // NewNeuron inits a neuron from an operation.
-func NewNeuron(op OperatorType) *Neuron {
-	return &Neuron{
-		Op:      op,
-		HasSeed: false,
-		Seed:    0,
+func NewNeuron[S Signal](op OperatorType) *Neuron[S] {
+	return &Neuron[S]{
+		Op:        op,
+		Kind:      PURE,
+		HasSeed:   false,
+		Seed:      0,
+		Threshold: DefaultThreshold,
 	}
 }
 
 // SetSeed accepts a seed value that will be used as an input to every Fire().
-func (n *Neuron) SetSeed(seed SignalType) {
+func (n *Neuron[S]) SetSeed(seed S) {
 	n.Seed = seed
 	n.HasSeed = true
 }
 
 // RemoveSeed doesn't actually change the Seed variable since the value will
 // only be respected if HasSeed is true.
-func (n *Neuron) RemoveSeed() {
+func (n *Neuron[S]) RemoveSeed() {
 	n.HasSeed = false
 }
 
 // Copy returns a copy of this Neuron's fields in a different pointer.
-func (n *Neuron) Copy() *Neuron {
-	return &Neuron{
-		Op:      n.Op,
-		HasSeed: n.HasSeed,
-		Seed:    n.Seed,
+func (n *Neuron[S]) Copy() *Neuron[S] {
+	c := &Neuron[S]{
+		Op:         n.Op,
+		Kind:       n.Kind,
+		HasSeed:    n.HasSeed,
+		Seed:       n.Seed,
+		Threshold:  n.Threshold,
+		Refractory: n.Refractory,
+	}
+	if n.Table != nil {
+		c.Table = make([]S, len(n.Table))
+		copy(c.Table, n.Table)
 	}
+	return c
+}
+
+// SetTable turns this into a TABLE neuron with a copy of the table, which
+// must have TableSize entries.
+func (n *Neuron[S]) SetTable(table []S) {
+	if len(table) != TableSize {
+		log.Fatalf("Table has %d entries instead of %d", len(table), TableSize)
+	}
+	n.Kind = TABLE
+	n.Table = make([]S, TableSize)
+	copy(n.Table, table)
 }
 
 // IsEquiv returns if all the Neuron fields are equivalent, even if the two
 // pointers differ. If they both don't have seeds set, then it doesn't matter
-// what value in the Seed field.
-func (n *Neuron) IsEquiv(other *Neuron) bool {
-	return n.Op == other.Op && n.HasSeed == other.HasSeed &&
-		(!n.HasSeed || (n.HasSeed && n.Seed == other.Seed))
+// what value in the Seed field. Likewise, tables only matter to TABLE neurons.
+func (n *Neuron[S]) IsEquiv(other *Neuron[S]) bool {
+	return n.Op == other.Op && n.Kind == other.Kind && n.HasSeed == other.HasSeed &&
+		n.Threshold == other.Threshold && n.Refractory == other.Refractory &&
+		(!n.HasSeed || (n.HasSeed && n.Seed == other.Seed)) &&
+		(n.Kind != TABLE || equalSignals(n.Table, other.Table))
+}
+
+func equalSignals[S Signal](a, b []S) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
 }
 
 // Fire runs the Neuron's operation on all the inputs, including the Seed.
-func (n *Neuron) Fire(inputs []SignalType) SignalType {
-	// Seed inputs are "sticky" so they come back for every trigger even when the
-	// rest of the inputs gets cleared.
+func (n *Neuron[S]) Fire(inputs []S) S {
+	inputs = n.operands(inputs)
+	if n.Kind == TABLE {
+		return n.lookup(inputs)
+	}
+	return Operate(n.Op, inputs)
+}
+
+// operands are the inputs that the Neuron actually operates on. Seed inputs
+// are "sticky" so they come back for every trigger even when the rest of the
+// inputs gets cleared. The seed is always the last input, for the sake of
+// ordered operators, and it counts against the op's MaxInputs first so that
+// it's never the one cut off. TABLE neurons look up every input.
+func (n *Neuron[S]) operands(inputs []S) []S {
+	max := 0
+	if n.Kind != TABLE {
+		max = n.Op.Operator().MaxInputs
+	}
 	if n.HasSeed {
-		inputs = append(inputs, n.Seed)
+		if max > 0 && len(inputs) >= max {
+			inputs = inputs[: max-1 : max-1]
+		}
+		return append(inputs, n.Seed)
+	}
+	if max > 0 && len(inputs) > max {
+		inputs = inputs[:max]
+	}
+	return inputs
+}
+
+// lookup finds the table entry for the inputs. A single input indexes the
+// table directly by its whole number part, and several inputs are hashed
+// together into an index.
+func (n *Neuron[S]) lookup(inputs []S) S {
+	if len(n.Table) != TableSize || len(inputs) == 0 {
+		return 0
+	}
+
+	w := WidthOf[S]()
+	if len(inputs) == 1 {
+		return n.Table[(Word(inputs[0])>>w.FracBits)%TableSize]
+	}
+
+	// FNV-1a, which is order sensitive like the input slots.
+	hash := Word(14695981039346656037)
+	for _, in := range inputs {
+		hash ^= Word(in)
+		hash *= 1099511628211
+	}
+	return n.Table[hash%TableSize]
+}
+
+// IsStateful returns true if the neuron carries state between firings.
+func (n *Neuron[S]) IsStateful() bool {
+	return n.Kind != PURE && n.Kind != TABLE
+}
+
+// Step fires the neuron using the state left over from its previous firing,
+// and returns the output along with the state for the next firing. The state
+// starts at zero, and is ignored by PURE neurons.
+func (n *Neuron[S]) Step(inputs []S, state S) (output S, next S) {
+	result := n.Fire(inputs)
+	switch n.Kind {
+	case LATCH:
+		if state == 0 {
+			state = result
+		}
+		return state, state
+	case ACCUMULATOR:
+		next = state + result
+		return next, next
+	case COUNTER:
+		if result != 0 {
+			// Count in whole numbers, even for fixed point signals.
+			state += S(WidthOf[S]().One())
+		}
+		return state, state
+	case MEMORY:
+		return state, result
+	default:
+		return result, state
 	}
-	return n.Op.Operate(inputs)
 }
 
 // Synapse is a simple representation of a neuron -> neuron connection.
@@ -1,66 +1,149 @@
 package neuron
 
 import (
+	"math"
 	"math/rand"
 	"testing"
 	"time"
 )
 
 func testOperator(t *testing.T, op OperatorType, inputs []SignalType, want SignalType) {
-	if got := op.Operate(inputs); got != want {
+	if got := Operate(op, inputs); got != want {
 		t.Errorf("Got wrong value for op %d: inputs %v, got %d, want %d", op, inputs, got, want)
 	}
 }
 
 func TestOperators(t *testing.T) {
 	testOperator(t, AND, []SignalType{9, 14}, 8)
-	testOperator(t, NAND, []SignalType{MaxSignal(), MaxSignal() - 4}, 4)
+	testOperator(t, NAND, []SignalType{MaxSignal[SignalType](), MaxSignal[SignalType]() - 4}, 4)
 	testOperator(t, OR, []SignalType{9, 10}, 11)
-	testOperator(t, NOR, []SignalType{MaxSignal() - 4, MaxSignal() - 4}, 4)
+	testOperator(t, NOR, []SignalType{MaxSignal[SignalType]() - 4, MaxSignal[SignalType]() - 4}, 4)
 	testOperator(t, XOR, []SignalType{11, 12}, 7)
-	testOperator(t, IFF, []SignalType{MaxSignal(), 4}, 4)
+	testOperator(t, IFF, []SignalType{MaxSignal[SignalType](), 4}, 4)
 	testOperator(t, ADD, []SignalType{5, 6, 7}, 18)
 	testOperator(t, MULTIPLY, []SignalType{5, 6, 2}, 60)
 	testOperator(t, GCF, []SignalType{12, 9}, 3)
 	testOperator(t, MAX, []SignalType{7, 9}, 9)
 	testOperator(t, MIN, []SignalType{7, 9}, 7)
-	testOperator(t, TRUTH, []SignalType{3, 7}, MaxSignal())
+	testOperator(t, TRUTH, []SignalType{3, 7}, MaxSignal[SignalType]())
 	testOperator(t, FALSIFY, []SignalType{3, 7}, 0)
+	testOperator(t, SUBTRACT, []SignalType{20, 3, 5}, 12)
+	testOperator(t, SUBTRACT, []SignalType{3, 5}, MaxSignal[SignalType]()-1)
+	testOperator(t, DIVIDE, []SignalType{100, 3, 2}, 16)
+	testOperator(t, DIVIDE, []SignalType{100, 0}, MaxSignal[SignalType]())
+	testOperator(t, MOD, []SignalType{100, 7}, 2)
+	testOperator(t, MOD, []SignalType{100, 0}, 100)
+	testOperator(t, SHIFT_LEFT, []SignalType{3, 2}, 12)
+	testOperator(t, SHIFT_LEFT, []SignalType{3, 8}, 0)
+	testOperator(t, SHIFT_RIGHT, []SignalType{200, 3}, 25)
+	testOperator(t, COMPARE, []SignalType{7, 3}, MaxSignal[SignalType]())
+	testOperator(t, COMPARE, []SignalType{3, 7}, 0)
+	testOperator(t, COMPARE, []SignalType{7, 7}, 0)
+	// The third input is past the max.
+	testOperator(t, COMPARE, []SignalType{7, 3, 100}, MaxSignal[SignalType]())
+	// One input is less than the min.
+	testOperator(t, COMPARE, []SignalType{7}, 0)
+
+	testOperator(t, SAT_ADD, []SignalType{100, 50}, 150)
+	testOperator(t, SAT_ADD, []SignalType{200, 100, 1}, MaxSignal[SignalType]())
+	testOperator(t, SAT_MUL, []SignalType{3, 4, 5}, 60)
+	testOperator(t, SAT_MUL, []SignalType{20, 20}, MaxSignal[SignalType]())
+	testOperator(t, SAT_MUL, []SignalType{20, 20, 0}, 0)
+	testOperator(t, AVERAGE, []SignalType{7, 10}, 8)
+	testOperator(t, AVERAGE, []SignalType{250, 254}, 252)
+	testOperator(t, AVERAGE, []SignalType{9}, 9)
+	testOperator(t, ABS_DIFF, []SignalType{3, 10}, 7)
+	testOperator(t, ABS_DIFF, []SignalType{10, 3}, 7)
+	testOperator(t, MUL_HIGH, []SignalType{200, 200}, 156)
+	testOperator(t, MUL_HIGH, []SignalType{15, 16}, 0)
+
+	// Fixed point division keeps the binary point in place.
+	if got, want := Operate(DIVIDE, []Fixed{FixedFromFloat(3), FixedFromFloat(4)}).Float(), 0.75; got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
 }
 
-func TestCommutative(t *testing.T) {
-	rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
-	for opVal := 0; opVal < NumOps; opVal++ {
+// randomSignal picks any signal of the type.
+func randomSignal[S Signal](rnd *rand.Rand) S {
+	return S(rnd.Int63n(int64(MaxSignal[S]()) + 1))
+}
+
+func testCommutative[S Signal](t *testing.T, rnd *rand.Rand) {
+	for opVal := 0; opVal < NumOps(); opVal++ {
 		op := InterpretOp(opVal)
+		if !op.Operator().Commutative {
+			continue
+		}
 		for i := 0; i < 100; i++ {
-			r1 := SignalType(rnd.Intn(int(MaxSignal())))
-			r2 := SignalType(rnd.Intn(int(MaxSignal())))
-			if op.Operate([]SignalType{r1, r2}) != op.Operate([]SignalType{r2, r1}) {
-				t.Errorf("Op %d is not commutative for %d and %d", opVal, r1, r2)
+			r1, r2 := randomSignal[S](rnd), randomSignal[S](rnd)
+			if Operate(op, []S{r1, r2}) != Operate(op, []S{r2, r1}) {
+				t.Errorf("Op %v is not commutative for %T %d and %d", op, r1, r1, r2)
 			}
 		}
 	}
 }
 
-func TestAssociative(t *testing.T) {
+func TestCommutative(t *testing.T) {
 	rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
-	for opVal := 0; opVal < NumOps; opVal++ {
+	testCommutative[uint8](t, rnd)
+	testCommutative[uint16](t, rnd)
+	testCommutative[uint32](t, rnd)
+	testCommutative[Fixed](t, rnd)
+}
+
+func testAssociative[S Signal](t *testing.T, rnd *rand.Rand) {
+	w := WidthOf[S]()
+	for opVal := 0; opVal < NumOps(); opVal++ {
 		op := InterpretOp(opVal)
+		def := op.Operator()
+		if !def.IsAssociative(w) {
+			continue
+		}
 		for i := 0; i < 50; i++ {
-			r1 := SignalType(rnd.Intn(int(MaxSignal())))
-			r2 := SignalType(rnd.Intn(int(MaxSignal())))
-			r3 := SignalType(rnd.Intn(int(MaxSignal())))
-			v1 := op.Operate([]SignalType{r1, r2, r3})
-			v2 := op.Operate([]SignalType{r2, r3, r1})
+			r1, r2, r3 := randomSignal[S](rnd), randomSignal[S](rnd), randomSignal[S](rnd)
+			combine := func(a, b Word) Word {
+				return w.Mask(def.Combine(a, b, w))
+			}
+			if v1, v2 := combine(combine(Word(r1), Word(r2)), Word(r3)), combine(Word(r1), combine(Word(r2), Word(r3))); v1 != v2 {
+				t.Errorf("Op %v is not associative for %T [%d, %d, %d], got %d vs %d", op, r1, r1, r2, r3, v1, v2)
+			}
+
+			// Being both commutative and associative means any order of the inputs
+			// gives the same result.
+			if !def.Commutative {
+				continue
+			}
+			v1 := Operate(op, []S{r1, r2, r3})
+			v2 := Operate(op, []S{r2, r3, r1})
 			if v1 != v2 {
-				t.Errorf("Op %v is not associative for [%d, %d, %d], got %d vs %d", op, r1, r2, r3, v1, v2)
+				t.Errorf("Op %v depends on order for %T [%d, %d, %d], got %d vs %d", op, r1, r1, r2, r3, v1, v2)
 			}
 		}
 	}
 }
 
+func TestAssociative(t *testing.T) {
+	rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
+	testAssociative[uint8](t, rnd)
+	testAssociative[uint16](t, rnd)
+	testAssociative[uint32](t, rnd)
+	testAssociative[Fixed](t, rnd)
+
+	// Fixed point products round off after every step, so the grouping shows.
+	if got, want := MULTIPLY.Operator().IsAssociative(WidthOf[Fixed]()), false; got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+	if got, want := MULTIPLY.Operator().IsAssociative(WidthOf[uint32]()), true; got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+	a, b, c := Fixed(3), FixedFromFloat(0.5), Fixed(1<<FixedFracBits+1<<(FixedFracBits-1))
+	if Operate(MULTIPLY, []Fixed{a, b, c}) == Operate(MULTIPLY, []Fixed{b, c, a}) {
+		t.Errorf("Want the order to change the rounding of %v, %v, %v", a, b, c)
+	}
+}
+
 func TestNeuronCopying(t *testing.T) {
-	a := NewNeuron(ADD)
+	a := NewNeuron[SignalType](ADD)
 	a.SetSeed(1)
 	b := a.Copy()
 	if a == b {
@@ -79,7 +162,7 @@ func TestNeuronCopying(t *testing.T) {
 }
 
 func TestFire(t *testing.T) {
-	n := NewNeuron(OR)
+	n := NewNeuron[SignalType](OR)
 	if got, want := n.Fire([]SignalType{1, 2, 3, 4, 5}), SignalType(7); got != want {
 		t.Errorf("Want %v, got %v", want, got)
 	}
@@ -89,3 +172,227 @@ func TestFire(t *testing.T) {
 		t.Errorf("Want %v, got %v", want, got)
 	}
 }
+
+func TestFireKeepsSeedUnderMaxInputs(t *testing.T) {
+	n := NewNeuron[SignalType](AVERAGE)
+	n.SetSeed(100)
+
+	// The seed takes one of the two inputs, so the second synapse input is the
+	// one that gets dropped.
+	inputs := []SignalType{20, 40}
+	if got, want := n.Fire(inputs), SignalType(60); got != want {
+		t.Errorf("Want %v, got %v", want, got)
+	}
+	if got, want := inputs[1], SignalType(40); got != want {
+		t.Errorf("Fire shouldn't change the inputs, got %v", inputs)
+	}
+	if got, want := n.Fire([]SignalType{20}), SignalType(60); got != want {
+		t.Errorf("Want %v, got %v", want, got)
+	}
+}
+
+func TestRegisterOperator(t *testing.T) {
+	op, err := RegisterOperator(Operator{
+		Name:      "TEST_AVERAGE_OF_TWO",
+		Identity:  ZeroIdentity,
+		MinInputs: 2,
+		MaxInputs: 2,
+		Combine: func(a, b Word, w Width) Word {
+			return a/2 + b/2
+		},
+	})
+	if err != nil {
+		t.Fatalf("Got error %v", err)
+	}
+	t.Cleanup(func() { registry.unregister(op) })
+
+	if got, ok := LookupOperator("TEST_AVERAGE_OF_TWO"); !ok || got != op {
+		t.Errorf("Got %v (found %v), want %v", got, ok, op)
+	}
+	if got, want := op.String(), "TEST_AVERAGE_OF_TWO"; got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+	if got, want := InterpretOp(NumOps()-1), op; got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+
+	// 0/2 + 8/2 = 4, then 4/2 + 20/2 = 12. The third input is over the max.
+	testOperator(t, op, []SignalType{8, 20, 100}, 12)
+	// Under the min inputs returns the identity.
+	testOperator(t, op, []SignalType{8}, 0)
+
+	if _, err := RegisterOperator(Operator{Name: "TEST_AVERAGE_OF_TWO", Identity: ZeroIdentity, Combine: ADD.Operator().Combine}); err == nil {
+		t.Errorf("Want error for duplicate name, got none")
+	}
+	if _, err := RegisterOperator(Operator{Name: "TEST_NO_COMBINE"}); err == nil {
+		t.Errorf("Want error for missing combine, got none")
+	}
+	if _, err := RegisterOperator(Operator{Name: "TEST_BAD_ARITY", MinInputs: 3, MaxInputs: 2, Identity: ZeroIdentity, Combine: ADD.Operator().Combine}); err == nil {
+		t.Errorf("Want error for bad arity, got none")
+	}
+}
+
+func TestSignalWidths(t *testing.T) {
+	if got, want := Operate(ADD, []uint8{200, 100}), uint8(44); got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+	if got, want := Operate(ADD, []uint16{200, 100}), uint16(300); got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+	if got, want := Operate(NAND, []uint16{MaxSignal[uint16](), 4}), uint16(65531); got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+	if got, want := Operate(TRUTH, []uint32{1, 2}), uint32(math.MaxUint32); got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+	if got, want := Operate(MULTIPLY, []uint32{70000, 3}), uint32(210000); got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+
+	// Fixed point multiplication keeps the binary point in place.
+	product := Operate(MULTIPLY, []Fixed{FixedFromFloat(1.5), FixedFromFloat(2.25)})
+	if got, want := product.Float(), 3.375; got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+	sum := Operate(ADD, []Fixed{FixedFromFloat(0.25), FixedFromFloat(0.5)})
+	if got, want := sum.Float(), 0.75; got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+	if got, want := FixedFromFloat(-1), Fixed(0); got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+
+	n := NewNeuron[uint16](ADD)
+	n.SetSeed(1000)
+	if got, want := n.Fire([]uint16{24}), uint16(1024); got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+}
+
+func TestOrderedOperatorsNotCommutative(t *testing.T) {
+	for _, op := range []OperatorType{SUBTRACT, DIVIDE, MOD, SHIFT_LEFT, SHIFT_RIGHT, COMPARE} {
+		if op.Operator().Commutative {
+			t.Errorf("Op %v shouldn't claim to be commutative", op)
+		}
+		if Operate(op, []SignalType{9, 2}) == Operate(op, []SignalType{2, 9}) {
+			t.Errorf("Op %v should depend on the order of its inputs", op)
+		}
+	}
+}
+
+func TestStatefulNeuronStep(t *testing.T) {
+	testcases := []struct {
+		kind    NeuronKind
+		inputs  [][]SignalType
+		outputs []SignalType
+	}{
+		{PURE, [][]SignalType{{1, 2}, {0, 0}, {3, 4}}, []SignalType{3, 0, 7}},
+		{LATCH, [][]SignalType{{0, 0}, {1, 2}, {3, 4}}, []SignalType{0, 3, 3}},
+		{ACCUMULATOR, [][]SignalType{{1, 2}, {0, 0}, {3, 4}}, []SignalType{3, 3, 10}},
+		{COUNTER, [][]SignalType{{1, 2}, {0, 0}, {3, 4}}, []SignalType{1, 1, 2}},
+		{MEMORY, [][]SignalType{{1, 2}, {0, 0}, {3, 4}}, []SignalType{0, 3, 0}},
+	}
+
+	for _, tc := range testcases {
+		n := NewNeuron[SignalType](OR)
+		n.Kind = tc.kind
+
+		state := SignalType(0)
+		for i, inputs := range tc.inputs {
+			var got SignalType
+			got, state = n.Step(inputs, state)
+			if want := tc.outputs[i]; got != want {
+				t.Errorf("Kind %v step %d: got %v, want %v", tc.kind, i, got, want)
+			}
+		}
+	}
+
+	counter := NewNeuron[Fixed](OR)
+	counter.Kind = COUNTER
+	if got, _ := counter.Step([]Fixed{1}, FixedFromFloat(2)); got.Float() != 3 {
+		t.Errorf("Got %v, want 3", got.Float())
+	}
+
+	a := NewNeuron[SignalType](OR)
+	a.Threshold = AllInputs
+	a.Refractory = 1
+	if got := a.Copy(); !a.IsEquiv(got) {
+		t.Errorf("Copy should keep the threshold and refractory period, got %+v", got)
+	}
+	b := a.Copy()
+	b.Refractory = 2
+	if a.IsEquiv(b) {
+		t.Errorf("Neurons with different refractory periods should not be equivalent")
+	}
+	b = a.Copy()
+	b.Kind = LATCH
+	if a.IsEquiv(b) {
+		t.Errorf("Neurons of different kinds should not be equivalent")
+	}
+}
+
+func TestTableNeuron(t *testing.T) {
+	table := make([]SignalType, TableSize)
+	for i := range table {
+		table[i] = SignalType(TableSize - 1 - i)
+	}
+
+	n := NewNeuron[SignalType](OR)
+	n.SetTable(table)
+	if got, want := n.Fire([]SignalType{3}), SignalType(252); got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+	if n.IsStateful() {
+		t.Errorf("Table neurons should not be stateful")
+	}
+
+	// Several inputs are hashed, which depends on their order.
+	if got, want := n.Fire([]SignalType{3, 4}), n.Fire([]SignalType{3, 4}); got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+	if n.Fire([]SignalType{3, 4}) == n.Fire([]SignalType{4, 3}) {
+		t.Errorf("Expected different entries for different input orders")
+	}
+
+	c := n.Copy()
+	if !n.IsEquiv(c) {
+		t.Errorf("Neuron values should be equivalent")
+	}
+	c.Table[0] = 1
+	if n.Table[0] == 1 {
+		t.Errorf("Copied tables should not share memory")
+	}
+	if n.IsEquiv(c) {
+		t.Errorf("Neurons with different tables should not be equivalent")
+	}
+
+	// Fixed point signals index the table with their whole number part.
+	fixedTable := make([]Fixed, TableSize)
+	fixedTable[3] = FixedFromFloat(0.5)
+	f := NewNeuron[Fixed](OR)
+	f.SetTable(fixedTable)
+	if got, want := f.Fire([]Fixed{FixedFromFloat(3.75)}).Float(), 0.5; got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+}
+
+func TestOperatorClaims(t *testing.T) {
+	// Non-associative ops that claim commutativity must be limited to two
+	// inputs, since folding any more would depend on their order.
+	for _, op := range RegisteredOps() {
+		def := op.Operator()
+		if def.Commutative && !def.Associative && def.MaxInputs != 2 {
+			t.Errorf("Op %v is commutative but not associative, with max inputs %d", op, def.MaxInputs)
+		}
+	}
+
+	if got, want := Operate(SAT_ADD, []uint16{60000, 6000}), MaxSignal[uint16](); got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+	if got, want := Operate(MUL_HIGH, []uint16{60000, 6000}), uint16(5493); got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+	if got, want := Operate(SAT_MUL, []Fixed{FixedFromFloat(1.5), FixedFromFloat(3)}).Float(), 4.5; got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+}
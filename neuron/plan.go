@@ -0,0 +1,333 @@
+package neuron
+
+import (
+	"log"
+	"sort"
+)
+
+// Plan is a DNA compiled into flat arrays, so that firing doesn't need any
+// maps or allocations for each step. Neurons are given a dense index in ID
+// order, and every input slot of every neuron gets a global slot index, with
+// the slots of each neuron next to each other in slot order. A Plan doesn't
+// change once it's compiled, and any number of brains can share one.
+type Plan[S Signal] struct {
+	ids      []IDType
+	neurons  []*Neuron[S]
+	stateful []bool
+
+	// sense has the index of each SENSE neuron, in vision order.
+	sense []int
+	// motor has the motor index of each neuron, or -1 if it isn't a MOTOR.
+	motor     []int
+	numMotors int
+
+	// The input slots of neuron i are slotStart[i] to slotStart[i+1], and
+	// slotNeuron maps each slot back to its neuron. SENSE neurons have their
+	// external input slot first. slotID is the synapse ID of each slot, or
+	// SenseSlot.
+	slotStart  []int
+	slotNeuron []int
+	slotID     []IDType
+
+	// The synapses out of neuron i are outStart[i] to outStart[i+1], and
+	// outSlot is the slot each one delivers to, in CSR form.
+	outStart []int
+	outSlot  []int
+}
+
+// Compile turns the DNA into a Plan. The neurons are copied, so the DNA can
+// change afterwards without affecting the plan.
+func Compile[S Signal](dna *DNA[S]) *Plan[S] {
+	ids := sortedNeuronIDs(dna)
+	index := make(map[IDType]int, len(ids))
+	p := &Plan[S]{
+		ids:       ids,
+		neurons:   make([]*Neuron[S], len(ids)),
+		stateful:  make([]bool, len(ids)),
+		sense:     make([]int, dna.Source.NeuronIDs[SENSE].Length()),
+		motor:     make([]int, len(ids)),
+		numMotors: dna.Source.NeuronIDs[MOTOR].Length(),
+		slotStart: make([]int, len(ids)+1),
+		outStart:  make([]int, len(ids)+1),
+	}
+	for i, id := range ids {
+		index[id] = i
+		p.neurons[i] = dna.Neurons[id].Copy()
+		p.stateful[i] = p.neurons[i].IsStateful()
+		p.motor[i] = -1
+	}
+
+	isSense := make([]bool, len(ids))
+	for visionIndex := range p.sense {
+		id := dna.Source.NeuronIDs[SENSE].GetID(visionIndex)
+		i, ok := index[id]
+		if !ok {
+			log.Fatalf("SENSE neuron %d is missing from the DNA", id)
+		}
+		p.sense[visionIndex] = i
+		isSense[i] = true
+	}
+	for motorIndex := 0; motorIndex < p.numMotors; motorIndex++ {
+		if i, ok := index[dna.Source.NeuronIDs[MOTOR].GetID(motorIndex)]; ok {
+			p.motor[i] = motorIndex
+		}
+	}
+
+	// Synapse IDs are the input slots, so sorting them gives the slot order.
+	inSynapses := make([][]IDType, len(ids))
+	for synID, syn := range dna.Synpases.idMap {
+		inSynapses[index[syn.dst]] = append(inSynapses[index[syn.dst]], synID)
+	}
+	slotOf := make(map[IDType]int, len(dna.Synpases.idMap))
+	p.slotNeuron = make([]int, 0, len(dna.Synpases.idMap)+len(p.sense))
+	p.slotID = make([]IDType, 0, cap(p.slotNeuron))
+	for i := range ids {
+		p.slotStart[i] = len(p.slotNeuron)
+		if isSense[i] {
+			p.slotNeuron = append(p.slotNeuron, i)
+			p.slotID = append(p.slotID, SenseSlot)
+		}
+		sort.Ints(inSynapses[i])
+		for _, synID := range inSynapses[i] {
+			slotOf[synID] = len(p.slotNeuron)
+			p.slotNeuron = append(p.slotNeuron, i)
+			p.slotID = append(p.slotID, synID)
+		}
+	}
+	p.slotStart[len(ids)] = len(p.slotNeuron)
+
+	p.outSlot = make([]int, 0, len(dna.Synpases.idMap))
+	for i, id := range ids {
+		p.outStart[i] = len(p.outSlot)
+		synIDs := make([]IDType, 0, len(dna.Synpases.srcMap[id]))
+		for synID := range dna.Synpases.srcMap[id] {
+			synIDs = append(synIDs, synID)
+		}
+		sort.Ints(synIDs)
+		for _, synID := range synIDs {
+			p.outSlot = append(p.outSlot, slotOf[synID])
+		}
+	}
+	p.outStart[len(ids)] = len(p.outSlot)
+	return p
+}
+
+// NewBrain creates a brain that fires using the plan.
+func (p *Plan[S]) NewBrain() *CompiledBrain[S] {
+	numNeurons := len(p.neurons)
+	return &CompiledBrain[S]{
+		plan:       p,
+		pending:    make([][]S, len(p.slotNeuron)),
+		numPending: make([]int, numNeurons),
+		numFilled:  make([]int, numNeurons),
+		active:     make([]int, 0, numNeurons),
+		isActive:   make([]bool, numNeurons),
+		next:       make([]delivery[S], 0, len(p.outSlot)),
+		inputs:     make([]S, 0, len(p.slotNeuron)+1),
+		state:      make([]S, numNeurons),
+		restUntil:  make([]int, numNeurons),
+		outputs:    make([][]S, p.numMotors),
+		terminated: make([]bool, p.numMotors),
+	}
+}
+
+// delivery is a signal sent along a synapse, waiting for the step to end.
+type delivery[S Signal] struct {
+	slot int
+	sig  S
+}
+
+// CompiledBrain fires exactly like a Brain grown from the same DNA, but all of
+// its buffers are allocated up front and reused from step to step.
+type CompiledBrain[S Signal] struct {
+	plan *Plan[S]
+
+	// pending holds the signals waiting in each slot, in the order they came.
+	pending [][]S
+	// numPending and numFilled are the number of signals and the number of
+	// slots with signals waiting for each neuron.
+	numPending []int
+	numFilled  []int
+	// active are the neurons with signals waiting, in no particular order.
+	active   []int
+	isActive []bool
+	// next holds the signals sent during a step, until the step is over.
+	next   []delivery[S]
+	inputs []S
+
+	state     []S
+	restUntil []int
+	steps     int
+	firings   int
+	// stepBudget and carry are the same as in Brain.
+	stepBudget int
+	carry      CarryPolicy
+
+	outputs    [][]S
+	terminated []bool
+}
+
+// Firings returns the number of times any neuron has fired since the brain
+// was created.
+func (b *CompiledBrain[S]) Firings() int {
+	return b.firings
+}
+
+// SetStepBudget works the same as Brain.SetStepBudget.
+func (b *CompiledBrain[S]) SetStepBudget(budget int) {
+	b.stepBudget = budget
+}
+
+// Fire works the same as Brain.Fire.
+func (b *CompiledBrain[S]) Fire(inputs [][]S) [][]S {
+	return b.FireResult(inputs).Outputs
+}
+
+// FireResult works the same as Brain.FireResult.
+func (b *CompiledBrain[S]) FireResult(inputs [][]S) FireResult[S] {
+	p := b.plan
+	budget := b.stepBudget
+	if budget <= 0 {
+		budget = DefaultStepBudget
+	}
+
+	result := FireResult[S]{
+		Exhausted: true,
+	}
+	inputStringIndex := 0
+	for result.Steps < budget {
+		for visionIndex, inputString := range inputs {
+			if visionIndex >= len(p.sense) || inputStringIndex > len(inputString) {
+				continue
+			}
+
+			var inputSignal S
+			if inputStringIndex < len(inputString) {
+				inputSignal = inputString[inputStringIndex]
+			} else {
+				inputSignal = NullRune
+			}
+			b.deliver(p.slotStart[p.sense[visionIndex]], inputSignal)
+		}
+		inputStringIndex++
+
+		b.step()
+		result.Steps++
+
+		allTerminated := true
+		for _, terminated := range b.terminated {
+			if !terminated {
+				allTerminated = false
+			}
+		}
+		if allTerminated {
+			result.Exhausted = false
+			break
+		}
+	}
+
+	result.Outputs = make([][]S, p.numMotors)
+	result.Terminated = make([]bool, p.numMotors)
+	for motorIndex := range result.Outputs {
+		result.Terminated[motorIndex] = b.terminated[motorIndex]
+		if b.terminated[motorIndex] {
+			result.Outputs[motorIndex] = make([]S, len(b.outputs[motorIndex]))
+			copy(result.Outputs[motorIndex], b.outputs[motorIndex])
+		} else {
+			result.Outputs[motorIndex] = make([]S, 0)
+		}
+		b.outputs[motorIndex] = b.outputs[motorIndex][:0]
+		b.terminated[motorIndex] = false
+	}
+	for _, i := range b.active {
+		result.Pending += b.numPending[i]
+	}
+	b.carryOver()
+	return result
+}
+
+func (b *CompiledBrain[S]) step() {
+	p := b.plan
+	b.next = b.next[:0]
+
+	// Neurons that don't fire stay active, and are moved up in place.
+	kept := 0
+	for _, i := range b.active {
+		if !b.isReady(i) {
+			b.active[kept] = i
+			kept++
+			continue
+		}
+		neuron := p.neurons[i]
+		b.restUntil[i] = b.steps + 1 + neuron.Refractory
+		b.firings++
+
+		inputs := b.inputs[:0]
+		for slot := p.slotStart[i]; slot < p.slotStart[i+1]; slot++ {
+			inputs = append(inputs, b.pending[slot]...)
+			b.pending[slot] = b.pending[slot][:0]
+		}
+		b.inputs = inputs
+		b.numPending[i] = 0
+		b.numFilled[i] = 0
+		b.isActive[i] = false
+
+		var output S
+		if p.stateful[i] {
+			output, b.state[i] = neuron.Step(inputs, b.state[i])
+		} else {
+			output = neuron.Fire(inputs)
+		}
+
+		if motorIndex := p.motor[i]; motorIndex >= 0 && !b.terminated[motorIndex] {
+			if output == NullRune {
+				b.terminated[motorIndex] = true
+			} else {
+				b.outputs[motorIndex] = append(b.outputs[motorIndex], output)
+			}
+		}
+
+		for out := p.outStart[i]; out < p.outStart[i+1]; out++ {
+			b.next = append(b.next, delivery[S]{slot: p.outSlot[out], sig: output})
+		}
+	}
+	b.active = b.active[:kept]
+
+	b.steps++
+
+	for _, d := range b.next {
+		b.deliver(d.slot, d.sig)
+	}
+}
+
+// isReady is the same as Brain.isReady.
+func (b *CompiledBrain[S]) isReady(i int) bool {
+	if b.steps < b.restUntil[i] {
+		return false
+	}
+
+	neuron := b.plan.neurons[i]
+	if neuron.Threshold == AllInputs {
+		return b.numFilled[i] >= b.plan.slotStart[i+1]-b.plan.slotStart[i]
+	}
+
+	numInputs := b.numPending[i]
+	if neuron.HasSeed {
+		numInputs++
+	}
+	return numInputs >= neuron.Threshold
+}
+
+// deliver adds the signal behind any others waiting in the same slot.
+func (b *CompiledBrain[S]) deliver(slot int, sig S) {
+	i := b.plan.slotNeuron[slot]
+	if len(b.pending[slot]) == 0 {
+		b.numFilled[i]++
+	}
+	b.pending[slot] = append(b.pending[slot], sig)
+	b.numPending[i]++
+	if !b.isActive[i] {
+		b.isActive[i] = true
+		b.active = append(b.active, i)
+	}
+}
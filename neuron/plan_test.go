@@ -0,0 +1,110 @@
+package neuron
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// randomTestDNA evolves the structure and genes of a DNA at random. Stateful
+// neurons rarely let the output terminate, so they're only sometimes used.
+func randomTestDNA(rnd *rand.Rand) *DNA[SignalType] {
+	config := createTestPlayConfig()
+	config.NumInputs = 1 + rnd.Intn(3)
+	config.NumOutputs = 1 + rnd.Intn(2)
+	config.NumVariants = 1
+	if rnd.Intn(2) == 0 {
+		config.Mconf.ChangeKind = 0.3
+		config.Mconf.ChangeTableEntry = 0.5
+	}
+	config.Mconf.ChangeThreshold = 0.3
+	config.Mconf.ChangeRefractory = 0.2
+
+	p := NewPlayground[SignalType](config)
+	p.rnd = rnd
+	p.InitDNA()
+	dna := p.codes[0]
+	for i := 0; i < 5; i++ {
+		p.shiftConglomerate()
+		p.mutateDNAStructure(dna)
+		p.mutateNeurons(dna)
+	}
+	return dna
+}
+
+func randomTestInputs(rnd *rand.Rand, numInputs int) [][]SignalType {
+	inputs := make([][]SignalType, numInputs)
+	for i := range inputs {
+		inputs[i] = make([]SignalType, rnd.Intn(4))
+		for j := range inputs[i] {
+			inputs[i][j] = SignalType(1 + rnd.Intn(int(MaxSignal[SignalType]())))
+		}
+	}
+	return inputs
+}
+
+func TestCompiledBrainMatchesBrain(t *testing.T) {
+	seed := time.Now().UnixNano()
+	rnd := rand.New(rand.NewSource(seed))
+	for trial := 0; trial < 30; trial++ {
+		dna := randomTestDNA(rnd)
+		brain := Flourish(dna)
+		compiled := Compile(dna).NewBrain()
+		budget := rnd.Intn(2 * DefaultStepBudget)
+		brain.SetStepBudget(budget)
+		compiled.SetStepBudget(budget)
+
+		// Several fires in a row, since pending signals, state and rests all
+		// carry over between them.
+		for fire := 0; fire < 6; fire++ {
+			if fire == 4 {
+				brain.Reset()
+				compiled.Reset()
+			}
+			inputs := randomTestInputs(rnd, dna.Source.NeuronIDs[SENSE].Length())
+			if got, want := compiled.FireResult(inputs), brain.FireResult(inputs); !reflect.DeepEqual(got, want) {
+				t.Fatalf("Seed %d, trial %d, fire %d: inputs %v got %v, want %v for:\n%s", seed, trial, fire, inputs, got, want, dna.PrettyPrint())
+			}
+			if got, want := compiled.Firings(), brain.Firings(); got != want {
+				t.Fatalf("Seed %d, trial %d, fire %d: got %v firings, want %v", seed, trial, fire, got, want)
+			}
+		}
+	}
+}
+
+func TestCompiledBrainFire(t *testing.T) {
+	b := Compile(SimpleTestDNA()).NewBrain()
+	if got, want := b.Fire([][]SignalType{{1}, {2}}), [][]SignalType{{3}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Want %v, got %v", want, got)
+	}
+	// Extra inputs without a vision neuron are ignored.
+	if got, want := b.Fire([][]SignalType{{4}, {1}, {7}}), [][]SignalType{{5}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Want %v, got %v", want, got)
+	}
+}
+
+func TestCompiledBrainStepAllocs(t *testing.T) {
+	// Every neuron in a loop has a seed, so they keep firing forever.
+	c := NewConglomerate()
+	c.AddVisionAndMotor(1, 1)
+	inter := c.AddInterNeuron(0)
+	loop := c.Synapses.AddNewSynapse(inter, 0)
+
+	d := NewDNA[SignalType](c)
+	for neuronID := 0; neuronID <= inter; neuronID++ {
+		d.AddNeuron(neuronID, ADD)
+		d.SetSeed(neuronID, 1)
+	}
+	for synID := range c.Synapses.idMap {
+		d.AddSynapse(synID)
+	}
+	d.RemoveSynapse(0)
+	d.AddSynapse(loop)
+
+	b := Compile(d).NewBrain()
+	b.Fire([][]SignalType{{1}})
+	if got := testing.AllocsPerRun(100, b.step); got != 0 {
+		t.Errorf("Got %v allocations per step, want 0", got)
+	}
+}
@@ -2,6 +2,7 @@ package neuron
 
 import (
 	"fmt"
+	"log"
 	"math"
 	"math/rand"
 	"sort"
@@ -21,15 +22,54 @@ type EvolutionConfig struct {
 	DistanceOperationFactor float32
 }
 
+// InitOpPolicy is an enum for how the first generation picks its ops.
+type InitOpPolicy int
+
+const (
+	// INIT_OR starts every neuron as OR.
+	INIT_OR InitOpPolicy = iota
+	// INIT_FIXED starts every neuron with the InitOp.
+	INIT_FIXED
+	// INIT_RANDOM draws every neuron's op from the weighted ops.
+	INIT_RANDOM
+)
+
 type MutationConfig struct {
 	AddNeuron  float32
 	AddSynapse float32
 
-	ChangeOp  float32
-	SetSeed   float32
-	UnsetSeed float32
+	ChangeOp   float32
+	ChangeKind float32
+	SetSeed    float32
+	UnsetSeed  float32
+
+	// Chance for a TABLE neuron to have one of its entries rewritten.
+	ChangeTableEntry float32
+
+	ChangeThreshold  float32
+	ChangeRefractory float32
+
+	// Ops are the operators that mutations can choose from for this run. When
+	// empty, every operator in the registry at the start of the run is used.
+	Ops []OperatorType
+	// OpWeights are the relative chances of picking each of the Ops. Ops
+	// without a weight get 1, and a weight of 0 excludes the op entirely.
+	OpWeights map[OperatorType]float32
+
+	InitPolicy InitOpPolicy
+	InitOp     OperatorType
+
+	// AdaptOps is the rate [0-1] that the op weights shift each generation
+	// towards the ops used by the highest scoring genomes. No op falls below
+	// MinAdaptedOpWeight of its weight from OpWeights, so it can still be
+	// picked later on.
+	AdaptOps float32
 }
 
+// MinAdaptedOpWeight is the fraction of an op's configured weight that it
+// keeps no matter how little it's used.
+const MinAdaptedOpWeight = 0.05
+
 type PlaygroundConfig struct {
 	// Initialization
 	NumInputs  int
@@ -37,6 +77,13 @@ type PlaygroundConfig struct {
 
 	// Running the playground
 	NumVariants int
+	// CompactEvery is the number of generations between compactions of the
+	// conglomerate, where 0 never compacts.
+	CompactEvery int
+	// Debug validates the conglomerate and every DNA after each Evolve, which
+	// is slow but catches broken invariants right where they happen. Evolve
+	// panics with an error wrapping the ValidationErrors when one is broken.
+	Debug bool
 
 	// Nested configs
 	Econf EvolutionConfig
@@ -49,45 +96,60 @@ type BrainScore struct {
 	// sorted, so the score needs to travel with the ID.
 	id    IDType
 	score ScoreType
+	// raw is the score straight from the game, before any costs.
+	raw ScoreType
 }
 
-type Species struct {
-	rep     *DNA
+type Species[S Signal] struct {
+	rep     *DNA[S]
 	scores  []BrainScore
 	fitness ScoreType
+	// repFitness is the raw fitness of the rep.
+	repFitness ScoreType
 }
 
-func (s *Species) Size() int {
+func (s *Species[S]) Size() int {
 	return len(s.scores)
 }
 
 // Playground handles the organization and evolution of DNA.
-type Playground struct {
+type Playground[S Signal] struct {
 	config  PlaygroundConfig
 	source  *Conglomerate
-	codes   map[IDType]*DNA
-	species map[IDType]*Species
+	codes   map[IDType]*DNA[S]
+	species map[IDType]*Species[S]
 	rnd     *rand.Rand
+
+	// opChoices are the ops mutations can pick, and opWeights are their
+	// current (possibly adapted) weights. Both are set on the first use.
+	opChoices []OperatorType
+	opWeights []float64
+
+	// generations counts the calls to Evolve.
+	generations int
+	// pinned are DNA from past generations that are still in use, such as a
+	// hall of fame, so Compact keeps them working.
+	pinned []*DNA[S]
 }
 
-func NewPlayground(config PlaygroundConfig) *Playground {
-	return &Playground{
+func NewPlayground[S Signal](config PlaygroundConfig) *Playground[S] {
+	return &Playground[S]{
 		config:  config,
 		source:  NewConglomerate(),
-		codes:   make(map[IDType]*DNA),
-		species: make(map[IDType]*Species),
+		codes:   make(map[IDType]*DNA[S]),
+		species: make(map[IDType]*Species[S]),
 		rnd:     rand.New(rand.NewSource(time.Now().UnixNano())),
 	}
 }
 
-func (p *Playground) InitDNA() {
+func (p *Playground[S]) InitDNA() {
 	p.source.AddVisionAndMotor(p.config.NumInputs, p.config.NumOutputs)
 	for id := 0; id < p.config.NumVariants; id++ {
-		dna := NewDNA(p.source)
+		dna := NewDNA[S](p.source)
 
 		for _, nType := range NeuronTypes {
 			for i := 0; i < p.source.NeuronIDs[nType].Length(); i++ {
-				dna.AddNeuron(p.source.NeuronIDs[nType].GetID(i), OR)
+				dna.AddNeuron(p.source.NeuronIDs[nType].GetID(i), p.initialOp())
 			}
 		}
 		for synID := range p.source.Synapses.idMap {
@@ -101,19 +163,20 @@ func (p *Playground) InitDNA() {
 	}
 }
 
-func (p *Playground) GetBrain(id IDType) *Brain {
+func (p *Playground[S]) GetBrain(id IDType) *Brain[S] {
 	return Flourish(p.codes[id])
 }
 
-func (p *Playground) Evolve(scores []BrainScore) {
+func (p *Playground[S]) Evolve(scores []BrainScore) {
 	fmt.Printf("Evolution beginning (at %v)\n", time.Now())
+	p.adaptOpWeights(scores)
 	p.shiftConglomerate()
 
 	fmt.Printf("Beginning speciation at %v\n", time.Now())
 	speciesOffspring := p.speciation(scores)
 	fmt.Printf("Species offspring (at %v): %v\n", time.Now(), speciesOffspring)
 
-	newCodes := make(map[IDType]*DNA, p.config.NumVariants)
+	newCodes := make(map[IDType]*DNA[S], p.config.NumVariants)
 	currentMaxID := 0
 	for speciesID, species := range p.species {
 		childCodes := p.reproduction(species, speciesOffspring[speciesID])
@@ -140,6 +203,7 @@ func (p *Playground) Evolve(scores []BrainScore) {
 		// Include one DNA from this generation to represent the species for the
 		// next gen.
 		species.rep = p.codes[species.scores[0].id]
+		species.repFitness = species.scores[0].raw
 		// Clear all members from the species since they are no longer needed.
 		species.scores = make([]BrainScore, 0)
 		species.fitness = 0
@@ -148,10 +212,43 @@ func (p *Playground) Evolve(scores []BrainScore) {
 	for id, code := range newCodes {
 		p.codes[id] = code
 	}
+
+	p.generations++
+	if p.config.CompactEvery > 0 && p.generations%p.config.CompactEvery == 0 {
+		report := p.Compact()
+		fmt.Printf("Compacted conglomerate (at %v): %+v\n", time.Now(), report)
+	}
+
+	if p.config.Debug {
+		if err := p.validate(); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// validate returns the first conglomerate or DNA that's invalid, wrapping its
+// ValidationErrors so a caller that recovers can still get at them with
+// errors.As.
+func (p *Playground[S]) validate() error {
+	if err := p.source.Validate(); err != nil {
+		return fmt.Errorf("invalid conglomerate after generation %d: %w", p.generations, err)
+	}
+	for _, id := range sortedIDs(p.codes) {
+		if err := p.codes[id].Validate(); err != nil {
+			return fmt.Errorf("invalid DNA %d after generation %d: %w\n%s", id, p.generations, err, p.codes[id].PrettyPrint())
+		}
+	}
+	for _, speciesID := range sortedIDs(p.species) {
+		rep := p.species[speciesID].rep
+		if err := rep.Validate(); err != nil {
+			return fmt.Errorf("invalid representative of species %d after generation %d: %w\n%s", speciesID, p.generations, err, rep.PrettyPrint())
+		}
+	}
+	return nil
 }
 
 // Break DNA into species based on the distance between their structures.
-func (p *Playground) speciation(scores []BrainScore) map[IDType]int {
+func (p *Playground[S]) speciation(scores []BrainScore) map[IDType]int {
 	// Figure out which species this genome belongs in.
 	for _, score := range scores {
 		foundSpecies := false
@@ -172,7 +269,7 @@ func (p *Playground) speciation(scores []BrainScore) map[IDType]int {
 
 		// No existing species matched, so create a new one.
 		if !foundSpecies {
-			p.species[nextSpeciesID] = &Species{
+			p.species[nextSpeciesID] = &Species[S]{
 				rep:    p.codes[score.id],
 				scores: []BrainScore{score},
 			}
@@ -203,19 +300,18 @@ func (p *Playground) speciation(scores []BrainScore) map[IDType]int {
 // having matching neuronIDs is rather meaningless for how the genomes will
 // operate, so this function attempts to compute the distance based on how
 // different their outcomes will be.
-func (p *Playground) dnaDistance(a, b *DNA) float32 {
+func (p *Playground[S]) dnaDistance(a, b *DNA[S]) float32 {
 	matchingEdges := 0
 	matchingOperations := 0
-	for synID := range a.Synpases.idMap {
-		if syn, ok := b.Synpases.idMap[synID]; ok {
-			matchingEdges++
-
-			// If the src and dst neuron for this edge match, then count it.
-			// This will naturally double count neurons, however it keeps with the
-			// theme of computing genome distance based on edges.
-			if a.Neurons[syn.src].IsEquiv(b.Neurons[syn.src]) && a.Neurons[syn.dst].IsEquiv(b.Neurons[syn.dst]) {
-				matchingOperations++
-			}
+	for _, synID := range sharedSynapses(a, b) {
+		syn := a.Synpases.idMap[synID]
+		matchingEdges++
+
+		// If the src and dst neuron for this edge match, then count it.
+		// This will naturally double count neurons, however it keeps with the
+		// theme of computing genome distance based on edges.
+		if a.Neurons[syn.src].IsEquiv(b.Neurons[syn.src]) && a.Neurons[syn.dst].IsEquiv(b.Neurons[syn.dst]) {
+			matchingOperations++
 		}
 	}
 
@@ -235,7 +331,20 @@ func (p *Playground) dnaDistance(a, b *DNA) float32 {
 	return p.config.Econf.DistanceEdgeFactor*edgeFactor + p.config.Econf.DistanceOperationFactor*neuronFactor
 }
 
-func (p *Playground) partitionOffspring() map[IDType]int {
+// sharedSynapses returns the IDs of the synapses that both DNA have, in
+// order.
+func sharedSynapses[S Signal](a, b *DNA[S]) []IDType {
+	shared := make([]IDType, 0)
+	for synID := range a.Synpases.idMap {
+		if _, ok := b.Synpases.idMap[synID]; ok {
+			shared = append(shared, synID)
+		}
+	}
+	sort.Ints(shared)
+	return shared
+}
+
+func (p *Playground[S]) partitionOffspring() map[IDType]int {
 	totalGenerationFitness := ScoreType(0)
 	for _, species := range p.species {
 		totalGenerationFitness += species.fitness
@@ -262,7 +371,7 @@ func (p *Playground) partitionOffspring() map[IDType]int {
 	return offspringPerSpecies
 }
 
-func (p *Playground) reproduction(species *Species, numOffspring int) map[IDType]*DNA {
+func (p *Playground[S]) reproduction(species *Species[S], numOffspring int) map[IDType]*DNA[S] {
 	// Sorts high to low (higher scores are better).
 	sort.Slice(species.scores, func(i, j int) bool {
 		return species.scores[i].score > species.scores[j].score
@@ -272,7 +381,7 @@ func (p *Playground) reproduction(species *Species, numOffspring int) map[IDType
 	dieOff := percentageOfWithMin1(species.Size(), p.config.Econf.BottomTierPercent)
 	species.scores = species.scores[:species.Size()-dieOff]
 
-	newCodes := make(map[IDType]*DNA, numOffspring)
+	newCodes := make(map[IDType]*DNA[S], numOffspring)
 
 	// Can't reproduce without enough parents.
 	if species.Size() < p.config.Econf.Parents {
@@ -316,24 +425,43 @@ func (p *Playground) reproduction(species *Species, numOffspring int) map[IDType
 }
 
 // Overlay DNA on the conglomerate to line up genes.
-func (p *Playground) createOffspring(parentScores []BrainScore) *DNA {
-	child := NewDNA(p.source)
+func (p *Playground[S]) createOffspring(parentScores []BrainScore) *DNA[S] {
+	child := NewDNA[S](p.source)
 
 	seenEdges := make(IDSet, p.source.Synapses.nextID)
 	for v := 0; v < p.source.NeuronIDs[SENSE].Length(); v++ {
 		visionID := p.source.NeuronIDs[SENSE].GetID(v)
 
 		// Add the vision neuron to the child first.
-		parentIndex := p.randomParentGene(parentScores)
-		child.SetNeuron(visionID, p.codes[parentScores[parentIndex].id].Neurons[visionID])
+		p.inheritNeuron(child, visionID, parentScores)
 
 		p.traverseEdges(visionID, parentScores, child, seenEdges)
 	}
 
+	// MOTOR neurons are passed on even when none of the synapses into them
+	// were, so every child has all of its outputs. Otherwise the child's brain
+	// gives the game fewer outputs than it asked for, and Validate reports the
+	// missing MOTOR.
+	for m := 0; m < p.source.NeuronIDs[MOTOR].Length(); m++ {
+		motorID := p.source.NeuronIDs[MOTOR].GetID(m)
+		if _, ok := child.Neurons[motorID]; ok {
+			continue
+		}
+		contenders := make([]BrainScore, 0, len(parentScores))
+		for _, parentScore := range parentScores {
+			if _, ok := p.codes[parentScore.id].Neurons[motorID]; ok {
+				contenders = append(contenders, parentScore)
+			}
+		}
+		if len(contenders) > 0 {
+			p.inheritNeuron(child, motorID, contenders)
+		}
+	}
+
 	return child
 }
 
-func (p *Playground) traverseEdges(neuronID IDType, parentScores []BrainScore, child *DNA, seenEdges IDSet) {
+func (p *Playground[S]) traverseEdges(neuronID IDType, parentScores []BrainScore, child *DNA[S], seenEdges IDSet) {
 	// fmt.Printf("Evaluating neuron %d\n", neuronID)
 
 	// Any parent that has the source neuron is a contender.
@@ -388,9 +516,8 @@ func (p *Playground) traverseEdges(neuronID IDType, parentScores []BrainScore, c
 			continue
 		}
 
-		dstIndex := p.randomParentGene(dstContenders)
-		child.SetNeuron(syn.dst, p.codes[dstContenders[dstIndex].id].Neurons[syn.dst])
-		// fmt.Printf("--Adding neuron %d from parent %d\n", syn.dst, dstIndex)
+		p.inheritNeuron(child, syn.dst, dstContenders)
+		// fmt.Printf("--Adding neuron %d from parents %v\n", syn.dst, dstContenders)
 
 		// Calling this function here makes this a DFS, which is already true
 		// anyway because to be a BFS, each new neuron would need to be added to a
@@ -401,13 +528,40 @@ func (p *Playground) traverseEdges(neuronID IDType, parentScores []BrainScore, c
 	}
 }
 
-func (p *Playground) shiftConglomerate() {
+// Pass on the neuron from a random parent, weighted by their scores. Lookup
+// tables are crossed over one entry at a time from every parent that has a
+// table for this neuron.
+func (p *Playground[S]) inheritNeuron(child *DNA[S], neuronID IDType, contenders []BrainScore) {
+	parentIndex := p.randomParentGene(contenders)
+	child.SetNeuron(neuronID, p.codes[contenders[parentIndex].id].Neurons[neuronID])
+
+	neuron := child.Neurons[neuronID]
+	if neuron.Kind != TABLE {
+		return
+	}
+
+	tableParents := make([]BrainScore, 0, len(contenders))
+	for _, contender := range contenders {
+		if p.codes[contender.id].Neurons[neuronID].Kind == TABLE {
+			tableParents = append(tableParents, contender)
+		}
+	}
+	for i := range neuron.Table {
+		tableIndex := p.randomParentGene(tableParents)
+		neuron.Table[i] = p.codes[tableParents[tableIndex].id].Neurons[neuronID].Table[i]
+	}
+}
+
+func (p *Playground[S]) shiftConglomerate() {
 	// Increase the number of neurons by the expansion percentage.
 	// neuronsToAdd := percentageOfWithMin1(p.source.NeuronIDs[INTER].Length(), p.config.Mconf.NeuronExpansion)
 	neuronsToAdd := int(math.Ceil(math.Log10(float64(p.source.NeuronIDs[INTER].Length() + 2))))
-	for i := 0; i < neuronsToAdd; i++ {
+	// Synapse IDs have gaps once the conglomerate has been compacted, so only
+	// the ones that are left can be picked.
+	synIDs := sortedSynapseIDs(p.source.Synapses)
+	for i := 0; i < neuronsToAdd && len(synIDs) > 0; i++ {
 		// Okay to add a neuron on the same synapse more than once.
-		synID := p.rnd.Intn(p.source.Synapses.nextID)
+		synID := synIDs[p.rnd.Intn(len(synIDs))]
 		newInterID := p.source.AddInterNeuron(synID)
 		fmt.Printf("Shifting conglomerate: Adding new neuron %d on syn %d\n", newInterID, synID)
 	}
@@ -453,7 +607,7 @@ func (p *Playground) shiftConglomerate() {
 	}
 }
 
-func (p *Playground) nearbyNeurons(hops int) map[IDType]IDSet {
+func (p *Playground[S]) nearbyNeurons(hops int) map[IDType]IDSet {
 	// Iterate through all the synapses to get every neighboring neuron,
 	// regardless of the direction.
 	neighbors := make(map[IDType]IDSet)
@@ -509,13 +663,13 @@ func (p *Playground) nearbyNeurons(hops int) map[IDType]IDSet {
 // Take a new offspring and (maybe) give it some new structure from the source.
 // The only mutations that can occur on the conglomerate involve at least one
 // INTER neuron, so all neuron and synapse candidates are based on those.
-func (p *Playground) mutateDNAStructure(dna *DNA) {
+func (p *Playground[S]) mutateDNAStructure(dna *DNA[S]) {
 	// Find every neuron in the conglomerate that's between two neurons that
 	// the DNA has. So the DNA needs the src and dst but not the middle neuron.
 	neuronCandidates := make([]IDType, 0)
-	newSyn1 := make([]Synapse, 0)
-	newSyn2 := make([]Synapse, 0)
-	oldSyn := make([]Synapse, 0)
+	newSyn1 := make([]IDType, 0)
+	newSyn2 := make([]IDType, 0)
+	oldSyn := make([]IDType, 0)
 	for src := range p.source.Synapses.srcMap {
 		if _, hasSrc := dna.Neurons[src]; !hasSrc {
 			continue
@@ -531,12 +685,21 @@ func (p *Playground) mutateDNAStructure(dna *DNA) {
 					continue
 				}
 
+				// Compact can remove the synapse that was split to make mid, and
+				// then there's nothing to replace.
+				newID1, err1 := p.source.Synapses.FindID(src, mid)
+				newID2, err2 := p.source.Synapses.FindID(mid, dst)
+				oldID, err3 := p.source.Synapses.FindID(src, dst)
+				if err1 != nil || err2 != nil || err3 != nil {
+					continue
+				}
+
 				// The same neuron ID may be added multiple times, but the surrounding
 				// synapses will be different.
 				neuronCandidates = append(neuronCandidates, mid)
-				newSyn1 = append(newSyn1, Synapse{src: src, dst: mid})
-				newSyn2 = append(newSyn2, Synapse{src: mid, dst: dst})
-				oldSyn = append(oldSyn, Synapse{src: src, dst: dst})
+				newSyn1 = append(newSyn1, newID1)
+				newSyn2 = append(newSyn2, newID2)
+				oldSyn = append(oldSyn, oldID)
 			}
 		}
 	}
@@ -547,12 +710,9 @@ func (p *Playground) mutateDNAStructure(dna *DNA) {
 		neuronID := neuronCandidates[rndIndex]
 		dna.AddNeuron(neuronID, p.randomOp())
 
-		newID1, _ := p.source.Synapses.FindID(newSyn1[rndIndex].src, newSyn1[rndIndex].dst)
-		dna.AddSynapse(newID1)
-		newID2, _ := p.source.Synapses.FindID(newSyn2[rndIndex].src, newSyn2[rndIndex].dst)
-		dna.AddSynapse(newID2)
-		oldID, _ := p.source.Synapses.FindID(oldSyn[rndIndex].src, oldSyn[rndIndex].dst)
-		dna.RemoveSynapse(oldID)
+		dna.AddSynapse(newSyn1[rndIndex])
+		dna.AddSynapse(newSyn2[rndIndex])
+		dna.RemoveSynapse(oldSyn[rndIndex])
 	}
 
 	synCandidates := make([]IDType, 0)
@@ -575,26 +735,168 @@ func (p *Playground) mutateDNAStructure(dna *DNA) {
 	}
 }
 
-func (p *Playground) mutateNeurons(dna *DNA) {
+func (p *Playground[S]) mutateNeurons(dna *DNA[S]) {
 	for _, neuron := range dna.Neurons {
 		if p.mutationOccurs(p.config.Mconf.ChangeOp) {
 			neuron.Op = p.randomOp()
 		}
 
+		if p.mutationOccurs(p.config.Mconf.ChangeKind) {
+			neuron.Kind = NeuronKinds[p.rnd.Intn(len(NeuronKinds))]
+			if neuron.Kind != TABLE {
+				// A table that's no longer used would still show up in hashes,
+				// saved genomes and diffs.
+				neuron.Table = nil
+			} else if len(neuron.Table) != TableSize {
+				neuron.SetTable(p.randomTable())
+			}
+		}
+
+		if neuron.Kind == TABLE && p.mutationOccurs(p.config.Mconf.ChangeTableEntry) {
+			neuron.Table[p.rnd.Intn(TableSize)] = p.randomSignal()
+		}
+
+		if p.mutationOccurs(p.config.Mconf.ChangeThreshold) {
+			// Picking 0 stands in for AllInputs.
+			neuron.Threshold = p.rnd.Intn(MaxThreshold + 1)
+			if neuron.Threshold == 0 {
+				neuron.Threshold = AllInputs
+			}
+		}
+
+		if p.mutationOccurs(p.config.Mconf.ChangeRefractory) {
+			neuron.Refractory = p.rnd.Intn(MaxRefractory + 1)
+		}
+
 		if p.mutationOccurs(p.config.Mconf.SetSeed) {
-			neuron.SetSeed(SignalType(p.rnd.Intn(int(MaxSignal()))))
+			neuron.SetSeed(p.randomSignal())
 		} else if p.mutationOccurs(p.config.Mconf.UnsetSeed) {
 			neuron.RemoveSeed()
 		}
 	}
 }
 
-func (p *Playground) mutationOccurs(chance float32) bool {
+func (p *Playground[S]) randomSignal() S {
+	return S(p.rnd.Int63n(int64(MaxSignal[S]())))
+}
+
+func (p *Playground[S]) randomTable() []S {
+	table := make([]S, TableSize)
+	for i := range table {
+		table[i] = p.randomSignal()
+	}
+	return table
+}
+
+func (p *Playground[S]) mutationOccurs(chance float32) bool {
 	return p.rnd.Float32() <= chance
 }
 
-func (p *Playground) randomOp() OperatorType {
-	return InterpretOp(p.rnd.Intn(NumOps))
+func (p *Playground[S]) initOpWeights() {
+	ops := p.config.Mconf.Ops
+	if len(ops) == 0 {
+		ops = RegisteredOps()
+	}
+
+	p.opChoices = make([]OperatorType, 0, len(ops))
+	p.opWeights = make([]float64, 0, len(ops))
+	for _, op := range ops {
+		weight := p.baseOpWeight(op)
+		if weight <= 0 {
+			continue
+		}
+		p.opChoices = append(p.opChoices, op)
+		p.opWeights = append(p.opWeights, weight)
+	}
+
+	if len(p.opChoices) == 0 {
+		log.Fatalf("No ops have a positive weight in %+v", p.config.Mconf)
+	}
+}
+
+// baseOpWeight is the weight of the op from OpWeights, before any adapting.
+func (p *Playground[S]) baseOpWeight(op OperatorType) float64 {
+	if w, ok := p.config.Mconf.OpWeights[op]; ok {
+		return float64(w)
+	}
+	return 1
+}
+
+func (p *Playground[S]) randomOp() OperatorType {
+	if p.opChoices == nil {
+		p.initOpWeights()
+	}
+
+	totalWeight := 0.0
+	for _, weight := range p.opWeights {
+		totalWeight += weight
+	}
+
+	rndVal := p.rnd.Float64() * totalWeight
+	for i, weight := range p.opWeights {
+		if rndVal < weight {
+			return p.opChoices[i]
+		}
+		rndVal -= weight
+	}
+	return p.opChoices[len(p.opChoices)-1]
+}
+
+func (p *Playground[S]) initialOp() OperatorType {
+	switch p.config.Mconf.InitPolicy {
+	case INIT_FIXED:
+		return p.config.Mconf.InitOp
+	case INIT_RANDOM:
+		return p.randomOp()
+	default:
+		return OR
+	}
+}
+
+// Shift the op weights towards the ops that show up in high scoring genomes.
+// Every neuron's op counts towards its usage in proportion to the score of
+// its genome. The weights are blended with the usage by the AdaptOps rate, so
+// unused ops fade away gradually, down to MinAdaptedOpWeight of their base
+// weight. Without that floor an unused op would never be picked again, and so
+// would never be used again either.
+func (p *Playground[S]) adaptOpWeights(scores []BrainScore) {
+	if p.config.Mconf.AdaptOps <= 0 {
+		return
+	}
+	if p.opChoices == nil {
+		p.initOpWeights()
+	}
+
+	usage := make(map[OperatorType]float64, len(p.opChoices))
+	totalUsage := 0.0
+	for _, score := range scores {
+		if score.score <= 0 {
+			continue
+		}
+		for _, neuron := range p.codes[score.id].Neurons {
+			// The op of a table neuron is never used.
+			if neuron.Kind == TABLE {
+				continue
+			}
+			usage[neuron.Op] += float64(score.score)
+			totalUsage += float64(score.score)
+		}
+	}
+	if totalUsage == 0 {
+		return
+	}
+
+	totalWeight := 0.0
+	for _, weight := range p.opWeights {
+		totalWeight += weight
+	}
+
+	rate := float64(p.config.Mconf.AdaptOps)
+	for i, op := range p.opChoices {
+		target := totalWeight * usage[op] / totalUsage
+		p.opWeights[i] = math.Max((1-rate)*p.opWeights[i]+rate*target, MinAdaptedOpWeight*p.baseOpWeight(op))
+	}
+	fmt.Printf("Adapted op weights for %v: %v\n", p.opChoices, p.opWeights)
 }
 
 func geneChance(scores []BrainScore) []float32 {
@@ -611,7 +913,7 @@ func geneChance(scores []BrainScore) []float32 {
 	return geneChance
 }
 
-func (p *Playground) randomParentGene(parentScores []BrainScore) int {
+func (p *Playground[S]) randomParentGene(parentScores []BrainScore) int {
 	rndVal := p.rnd.Float32()
 	var dstIndex int
 	for index, chance := range geneChance(parentScores) {
@@ -8,7 +8,7 @@ import (
 )
 
 func TestInitDNA(t *testing.T) {
-	p := NewPlayground(PlaygroundConfig{
+	p := NewPlayground[SignalType](PlaygroundConfig{
 		NumInputs:   2,
 		NumOutputs:  1,
 		NumVariants: 5,
@@ -73,8 +73,8 @@ func createTestPlayConfig() PlaygroundConfig {
 	}
 }
 
-func CreateTestPlayground() *Playground {
-	p := NewPlayground(createTestPlayConfig())
+func CreateTestPlayground() *Playground[SignalType] {
+	p := NewPlayground[SignalType](createTestPlayConfig())
 	p.InitDNA() // Makes vision N0, N1, and motor N2 with Syn0 and Syn1.
 
 	p.source.AddInterNeuron(0) // Syn0 connects N0 to N2, makes N3 (Syn2 and Syn3)
@@ -116,7 +116,7 @@ func TestSpeciation(t *testing.T) {
 		scores[i] = BrainScore{id: i, score: ScoreType(p.rnd.Intn(100))}
 	}
 
-	p.species[0] = &Species{
+	p.species[0] = &Species[SignalType]{
 		rep: p.codes[0],
 	}
 
@@ -139,7 +139,7 @@ func TestSpeciation(t *testing.T) {
 func TestDNADistance(t *testing.T) {
 	p := CreateTestPlayground()
 
-	a := NewDNA(p.source)
+	a := NewDNA[SignalType](p.source)
 	a.AddNeuron(0, OR)
 	a.AddNeuron(1, FALSIFY)
 	a.AddNeuron(2, OR)
@@ -152,7 +152,7 @@ func TestDNADistance(t *testing.T) {
 	a.AddSynapse(5)
 	a.AddSynapse(8)
 
-	b := NewDNA(p.source)
+	b := NewDNA[SignalType](p.source)
 	b.AddNeuron(0, OR)
 	b.AddNeuron(1, OR)
 	b.AddNeuron(2, OR)
@@ -187,11 +187,11 @@ func TestPartitionOffspring(t *testing.T) {
 	// NumVariants is 10, total fitness is 100.
 	// baseValue = 0.1
 	// 94 * 0.1 = 6.4, gets rounded down to 9 with 0.4 remainder.
-	p.species[0] = &Species{fitness: 94}
+	p.species[0] = &Species[SignalType]{fitness: 94}
 	// 3 * 0.1 + 0.4 = 0.7, gets rounded up to 1 with -0.3 remainder.
-	p.species[1] = &Species{fitness: 3}
+	p.species[1] = &Species[SignalType]{fitness: 3}
 	// 3 * 0.1 - 0.3 = 0, gets rounded down to 0 with 0.0 remainder.
-	p.species[2] = &Species{fitness: 3}
+	p.species[2] = &Species[SignalType]{fitness: 3}
 
 	result := p.partitionOffspring()
 
@@ -214,9 +214,9 @@ func TestPartitionOffspring(t *testing.T) {
 func TestPartitionOffspringAllZero(t *testing.T) {
 	p := CreateTestPlayground()
 
-	p.species[0] = &Species{fitness: 1}
-	// p.species[1] = &Species{fitness: 1}
-	// p.species[2] = &Species{fitness: 1}
+	p.species[0] = &Species[SignalType]{fitness: 1}
+	// p.species[1] = &Species[SignalType]{fitness: 1}
+	// p.species[2] = &Species[SignalType]{fitness: 1}
 
 	result := p.partitionOffspring()
 
@@ -230,7 +230,7 @@ func TestPartitionOffspringAllZero(t *testing.T) {
 
 func TestReproduction(t *testing.T) {
 	p := CreateTestPlayground()
-	species := &Species{
+	species := &Species[SignalType]{
 		scores: []BrainScore{
 			{id: 0, score: 200},
 			{id: 1, score: 400},
@@ -274,6 +274,31 @@ func TestCreateOffspring(t *testing.T) {
 	}
 }
 
+func TestCreateOffspringKeepsMotors(t *testing.T) {
+	p := NewPlayground[SignalType](createTestPlayConfig())
+	p.InitDNA()
+
+	// Neither parent has a synapse into the MOTOR neuron, so crossover never
+	// reaches it by following synapses.
+	for _, id := range []IDType{0, 1} {
+		p.codes[id].RemoveSynapse(0)
+		p.codes[id].RemoveSynapse(1)
+		p.codes[id].Neurons[2].Op = XOR
+	}
+
+	child := p.createOffspring([]BrainScore{{id: 0, score: 60}, {id: 1, score: 40}})
+	motor, ok := child.Neurons[2]
+	if !ok {
+		t.Fatalf("Child didn't get a motor neuron")
+	}
+	if got, want := motor.Op, XOR; got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+	if err := child.Validate(); err != nil {
+		t.Errorf("Got error %v", err)
+	}
+}
+
 func TestShiftConglomerate(t *testing.T) {
 	p := CreateTestPlayground()
 
@@ -332,7 +357,7 @@ func TestNearbyNeurons(t *testing.T) {
 }
 
 func TestMutateDNAStructure(t *testing.T) {
-	p := NewPlayground(PlaygroundConfig{
+	p := NewPlayground[SignalType](PlaygroundConfig{
 		NumInputs:   2,
 		NumOutputs:  1,
 		NumVariants: 1,
@@ -396,7 +421,7 @@ func TestMutateNeurons(t *testing.T) {
 }
 
 func TestHelperFns(t *testing.T) {
-	p := NewPlayground(PlaygroundConfig{})
+	p := NewPlayground[SignalType](PlaygroundConfig{})
 
 	if got, want := p.mutationOccurs(1.0), true; got != want {
 		t.Errorf("Got %v, want %v", got, want)
@@ -436,3 +461,266 @@ func TestHelperFns(t *testing.T) {
 		t.Errorf("Got %v, want %v", got, want)
 	}
 }
+
+func TestRandomOpFromConfig(t *testing.T) {
+	p := NewPlayground[SignalType](PlaygroundConfig{
+		Mconf: MutationConfig{
+			Ops: []OperatorType{XOR, ADD},
+		},
+	})
+
+	for i := 0; i < 50; i++ {
+		if op := p.randomOp(); op != XOR && op != ADD {
+			t.Fatalf("Got op %v, want XOR or ADD", op)
+		}
+	}
+}
+
+func TestMutateNeuronsWideSeeds(t *testing.T) {
+	p := NewPlayground[uint16](PlaygroundConfig{
+		NumInputs:   2,
+		NumOutputs:  1,
+		NumVariants: 20,
+
+		Mconf: MutationConfig{
+			SetSeed: 1.0,
+		},
+	})
+	p.InitDNA()
+
+	// With 60 random seeds up to 65535, at least one should be over a byte.
+	foundWide := false
+	for _, dna := range p.codes {
+		for _, n := range dna.Neurons {
+			if n.Seed > math.MaxUint8 {
+				foundWide = true
+			}
+		}
+	}
+	if !foundWide {
+		t.Errorf("Expected seeds wider than a byte")
+	}
+}
+
+func TestMutateNeuronKinds(t *testing.T) {
+	p := NewPlayground[SignalType](PlaygroundConfig{
+		Mconf: MutationConfig{
+			ChangeKind: 1.0,
+		},
+	})
+
+	foundStateful := false
+	for i := 0; i < 20; i++ {
+		dna := SimpleTestDNA()
+		p.mutateNeurons(dna)
+		for _, n := range dna.Neurons {
+			if n.IsStateful() {
+				foundStateful = true
+			}
+		}
+	}
+	if !foundStateful {
+		t.Errorf("Expected some neurons to become stateful")
+	}
+}
+
+func TestInheritTable(t *testing.T) {
+	p := NewPlayground[SignalType](PlaygroundConfig{})
+	ones := make([]SignalType, TableSize)
+	twos := make([]SignalType, TableSize)
+	for i := 0; i < TableSize; i++ {
+		ones[i] = 1
+		twos[i] = 2
+	}
+	p.codes[0] = SimpleTestDNA()
+	p.codes[0].Neurons[2].SetTable(ones)
+	p.codes[1] = SimpleTestDNA()
+	p.codes[1].Neurons[2].SetTable(twos)
+
+	child := NewDNA[SignalType](p.codes[0].Source)
+	p.inheritNeuron(child, 2, []BrainScore{{id: 0, score: 50}, {id: 1, score: 50}})
+
+	counts := make(map[SignalType]int)
+	for _, entry := range child.Neurons[2].Table {
+		counts[entry]++
+	}
+	if counts[1] == 0 || counts[2] == 0 || counts[1]+counts[2] != TableSize {
+		t.Errorf("Expected entries from both parents, got %v", counts)
+	}
+	if p.codes[0].Neurons[2].Table[0] != 1 || p.codes[1].Neurons[2].Table[0] != 2 {
+		t.Errorf("Parent tables should not be changed")
+	}
+}
+
+func TestMutateTable(t *testing.T) {
+	p := NewPlayground[SignalType](PlaygroundConfig{
+		Mconf: MutationConfig{
+			ChangeTableEntry: 1.0,
+		},
+	})
+
+	dna := SimpleTestDNA()
+	dna.Neurons[2].SetTable(make([]SignalType, TableSize))
+	for i := 0; i < 20; i++ {
+		p.mutateNeurons(dna)
+	}
+
+	changed := 0
+	for _, entry := range dna.Neurons[2].Table {
+		if entry != 0 {
+			changed++
+		}
+	}
+	if changed == 0 {
+		t.Errorf("Expected table entries to be mutated")
+	}
+
+	p.config.Mconf = MutationConfig{ChangeKind: 1.0}
+	for i := 0; i < 50; i++ {
+		p.mutateNeurons(dna)
+		for id, n := range dna.Neurons {
+			if n.Kind == TABLE && len(n.Table) != TableSize {
+				t.Fatalf("Neuron %d became a table without entries", id)
+			}
+			if n.Kind != TABLE && n.Table != nil {
+				t.Fatalf("Neuron %d kept its table as a %v", id, n.Kind)
+			}
+		}
+	}
+}
+
+func TestMutateTiming(t *testing.T) {
+	p := NewPlayground[SignalType](PlaygroundConfig{
+		Mconf: MutationConfig{
+			ChangeThreshold:  1.0,
+			ChangeRefractory: 1.0,
+		},
+	})
+
+	dna := SimpleTestDNA()
+	changed := false
+	for i := 0; i < 20; i++ {
+		p.mutateNeurons(dna)
+		for _, n := range dna.Neurons {
+			if n.Threshold != AllInputs && (n.Threshold < 1 || n.Threshold > MaxThreshold) {
+				t.Fatalf("Got threshold %d", n.Threshold)
+			}
+			if n.Refractory < 0 || n.Refractory > MaxRefractory {
+				t.Fatalf("Got refractory period %d", n.Refractory)
+			}
+			if !n.IsEquiv(NewNeuron[SignalType](n.Op)) && n.Kind == PURE && !n.HasSeed {
+				changed = true
+			}
+		}
+	}
+	if !changed {
+		t.Errorf("Expected the timing of some neurons to change")
+	}
+}
+
+func TestRandomOpWeights(t *testing.T) {
+	p := NewPlayground[SignalType](PlaygroundConfig{
+		Mconf: MutationConfig{
+			OpWeights: map[OperatorType]float32{
+				TRUTH:   0,
+				FALSIFY: 0,
+				ADD:     1000,
+			},
+		},
+	})
+
+	counts := make(map[OperatorType]int)
+	for i := 0; i < 500; i++ {
+		counts[p.randomOp()]++
+	}
+	if counts[TRUTH] != 0 || counts[FALSIFY] != 0 {
+		t.Errorf("Excluded ops were picked: %v", counts)
+	}
+	if counts[ADD] < 400 {
+		t.Errorf("Expected ADD to be picked most of the time, got %v", counts)
+	}
+}
+
+func TestInitOpPolicy(t *testing.T) {
+	config := PlaygroundConfig{
+		NumInputs:   2,
+		NumOutputs:  1,
+		NumVariants: 5,
+	}
+
+	p := NewPlayground[SignalType](config)
+	p.InitDNA()
+	if got, want := p.codes[0].Neurons[2].Op, OR; got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+
+	config.Mconf.InitPolicy = INIT_FIXED
+	config.Mconf.InitOp = XOR
+	p = NewPlayground[SignalType](config)
+	p.InitDNA()
+	if got, want := p.codes[0].Neurons[2].Op, XOR; got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+
+	config.Mconf.InitPolicy = INIT_RANDOM
+	config.Mconf.Ops = []OperatorType{MIN, MAX}
+	p = NewPlayground[SignalType](config)
+	p.InitDNA()
+	for _, dna := range p.codes {
+		for _, n := range dna.Neurons {
+			if n.Op != MIN && n.Op != MAX {
+				t.Errorf("Got %v, want MIN or MAX", n.Op)
+			}
+		}
+	}
+}
+
+func TestAdaptOpWeights(t *testing.T) {
+	p := NewPlayground[SignalType](PlaygroundConfig{
+		Mconf: MutationConfig{
+			Ops:      []OperatorType{ADD, XOR},
+			AdaptOps: 0.5,
+		},
+	})
+
+	p.codes[0] = SimpleTestDNA()
+	p.codes[1] = SimpleTestDNA()
+	for _, n := range p.codes[0].Neurons {
+		n.Op = ADD
+	}
+	for _, n := range p.codes[1].Neurons {
+		n.Op = XOR
+	}
+
+	// The ADD genome scores 3 times higher, so ADD gets 3/4 of the usage.
+	p.adaptOpWeights([]BrainScore{{id: 0, score: 300}, {id: 1, score: 100}})
+	want := []float64{0.5*1 + 0.5*2*0.75, 0.5*1 + 0.5*2*0.25}
+	if !reflect.DeepEqual(p.opWeights, want) {
+		t.Errorf("Got %v, want %v", p.opWeights, want)
+	}
+}
+
+func TestAdaptOpWeightsKeepsUnusedOps(t *testing.T) {
+	p := NewPlayground[SignalType](PlaygroundConfig{
+		Mconf: MutationConfig{
+			Ops:      []OperatorType{OR, XOR},
+			AdaptOps: 1,
+		},
+	})
+	p.codes[0] = SimpleTestDNA() // Only uses OR.
+
+	for gen := 0; gen < 10; gen++ {
+		p.adaptOpWeights([]BrainScore{{id: 0, score: 100}})
+	}
+	if got, want := p.opWeights[1], MinAdaptedOpWeight; got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+
+	foundXOR := false
+	for i := 0; i < 1000 && !foundXOR; i++ {
+		foundXOR = p.randomOp() == XOR
+	}
+	if !foundXOR {
+		t.Errorf("Expected XOR to still be picked after adapting")
+	}
+}
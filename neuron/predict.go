@@ -0,0 +1,107 @@
+package neuron
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+)
+
+// ErrStepBudget is returned when the step budget ran out before every output
+// was terminated. The outputs that did finish are still returned with it.
+var ErrStepBudget = errors.New("step budget ran out before every output was terminated")
+
+// PredictorConfig changes how a Predictor fires.
+type PredictorConfig struct {
+	// StepBudget is the most steps for each prediction, or the
+	// DefaultStepBudget when it's 0.
+	StepBudget int
+	// Workers is the most predictions PredictBatch makes at once, or
+	// GOMAXPROCS when it's 0.
+	Workers int
+}
+
+// Predictor uses a trained DNA as a function from inputs to outputs, without
+// needing a Game. Every prediction starts from a fresh brain, so it's safe to
+// call from any number of goroutines at once and one prediction never affects
+// another.
+type Predictor[S Signal] struct {
+	plan   *Plan[S]
+	config PredictorConfig
+}
+
+// NewPredictor compiles the DNA, which can change afterwards without
+// affecting the Predictor. It returns an error if the DNA is invalid.
+func NewPredictor[S Signal](dna *DNA[S], config PredictorConfig) (*Predictor[S], error) {
+	if err := dna.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid DNA: %w", err)
+	}
+	return &Predictor[S]{
+		plan:   Compile(dna),
+		config: config,
+	}, nil
+}
+
+// NumInputs is the number of input strings that every prediction takes, one
+// for each SENSE neuron.
+func (p *Predictor[S]) NumInputs() int {
+	return len(p.plan.sense)
+}
+
+// NumOutputs is the number of output strings that every prediction gives, one
+// for each MOTOR neuron.
+func (p *Predictor[S]) NumOutputs() int {
+	return p.plan.numMotors
+}
+
+// Predict fires a fresh brain with the inputs and returns its outputs. The
+// error wraps ErrStepBudget if an output never terminated.
+func (p *Predictor[S]) Predict(inputs [][]S) ([][]S, error) {
+	if len(inputs) != p.NumInputs() {
+		return nil, fmt.Errorf("got %d inputs, want %d", len(inputs), p.NumInputs())
+	}
+
+	brain := p.plan.NewBrain()
+	brain.SetStepBudget(p.config.StepBudget)
+	result := brain.FireResult(inputs)
+	if result.Exhausted {
+		return result.Outputs, fmt.Errorf("after %d steps: %w", result.Steps, ErrStepBudget)
+	}
+	return result.Outputs, nil
+}
+
+// PredictBatch makes a prediction for each of the inputs at the same time,
+// spread over the Workers. Every prediction is made even if some fail, and the
+// error is from the first input that failed.
+func (p *Predictor[S]) PredictBatch(batch [][][]S) ([][][]S, error) {
+	workers := p.config.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	outputs := make([][][]S, len(batch))
+	errs := make([]error, len(batch))
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				outputs[i], errs[i] = p.Predict(batch[i])
+			}
+		}()
+	}
+	for i := range batch {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return outputs, fmt.Errorf("input %d: %w", i, err)
+		}
+	}
+	return outputs, nil
+}
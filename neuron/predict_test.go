@@ -0,0 +1,95 @@
+package neuron
+
+import (
+	"errors"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPredict(t *testing.T) {
+	d := SimpleTestDNA()
+	p, err := NewPredictor(d, PredictorConfig{})
+	if err != nil {
+		t.Fatalf("Got error %v", err)
+	}
+	if got, want := p.NumInputs(), 2; got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+	if got, want := p.NumOutputs(), 1; got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+
+	// Nothing carries over from one prediction to the next.
+	for i := 0; i < 2; i++ {
+		got, err := p.Predict([][]SignalType{{1}, {2}})
+		if err != nil {
+			t.Fatalf("Got error %v", err)
+		}
+		if want := [][]SignalType{{3}}; !reflect.DeepEqual(got, want) {
+			t.Errorf("Got %v, want %v", got, want)
+		}
+	}
+
+	// Changing the DNA doesn't change the predictor.
+	d.Neurons[2].Op = AND
+	if got, _ := p.Predict([][]SignalType{{1}, {2}}); !reflect.DeepEqual(got, [][]SignalType{{3}}) {
+		t.Errorf("Got %v, want %v", got, [][]SignalType{{3}})
+	}
+
+	if _, err := p.Predict([][]SignalType{{1}}); err == nil {
+		t.Errorf("Want an error for too few inputs")
+	}
+
+	// Two steps are one short of the output being terminated.
+	short, err := NewPredictor(SimpleTestDNA(), PredictorConfig{StepBudget: 2})
+	if err != nil {
+		t.Fatalf("Got error %v", err)
+	}
+	if _, err := short.Predict([][]SignalType{{1}, {2}}); !errors.Is(err, ErrStepBudget) {
+		t.Errorf("Got %v, want %v", err, ErrStepBudget)
+	}
+
+	invalid := SimpleTestDNA()
+	delete(invalid.Neurons, 2)
+	if _, err := NewPredictor(invalid, PredictorConfig{}); err == nil {
+		t.Errorf("Want an error for invalid DNA")
+	}
+}
+
+func TestPredictBatch(t *testing.T) {
+	seed := time.Now().UnixNano()
+	rnd := rand.New(rand.NewSource(seed))
+	for trial := 0; trial < 10; trial++ {
+		dna := randomTestDNA(rnd)
+		p, err := NewPredictor(dna, PredictorConfig{Workers: 1 + rnd.Intn(4)})
+		if err != nil {
+			t.Fatalf("Seed %d, trial %d: got error %v", seed, trial, err)
+		}
+
+		batch := make([][][]SignalType, 20)
+		want := make([][][]SignalType, len(batch))
+		var wantErr error
+		for i := range batch {
+			batch[i] = randomTestInputs(rnd, p.NumInputs())
+			want[i] = Flourish(dna).Fire(batch[i])
+			if _, err := p.Predict(batch[i]); err != nil && wantErr == nil {
+				wantErr = err
+			}
+		}
+
+		got, err := p.PredictBatch(batch)
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("Seed %d, trial %d: got %v, want %v", seed, trial, got, want)
+		}
+		if got, want := err != nil, wantErr != nil; got != want {
+			t.Errorf("Seed %d, trial %d: got error %v, want %v", seed, trial, err, wantErr)
+		}
+	}
+
+	p, _ := NewPredictor(SimpleTestDNA(), PredictorConfig{})
+	if _, err := p.PredictBatch([][][]SignalType{{{1}, {2}}, {{1}}}); err == nil {
+		t.Errorf("Want an error for too few inputs")
+	}
+}
@@ -0,0 +1,210 @@
+package neuron
+
+// absorbingSeeds are the ops that always give the same result once a certain
+// seed is one of the inputs, regardless of the other inputs. The first value
+// is the seed and the second is the result, which are either 0 or the max.
+var absorbingSeeds = map[OperatorType][2]bool{
+	AND:      {false, false},
+	NAND:     {false, true},
+	OR:       {true, true},
+	NOR:      {true, false},
+	MULTIPLY: {false, false},
+	MIN:      {false, false},
+	MAX:      {true, true},
+	SAT_ADD:  {true, true},
+	SAT_MUL:  {false, false},
+}
+
+// idempotentOps give back the input when all of their inputs are the same.
+var idempotentOps = map[OperatorType]bool{
+	AND: true,
+	OR:  true,
+	MIN: true,
+	MAX: true,
+	GCF: true,
+}
+
+// Prune returns a simplified copy of the DNA that fires exactly the same way.
+// Neurons that can never fire, either because no signal can reach them or
+// because they wait on AllInputs from a neuron that never fires, are removed
+// along with any neuron that can't reach a MOTOR neuron. SENSE and MOTOR
+// neurons are always kept. Neurons that always give 0 or the max signal are
+// then turned into FALSIFY or TRUTH neurons.
+func (d *DNA[S]) Prune() *DNA[S] {
+	pruned := d.DeepCopy()
+	live := pruned.liveNeurons()
+
+	for neuronID := range pruned.Neurons {
+		if _, ok := live[neuronID]; !ok && !pruned.isSenseOrMotor(neuronID) {
+			delete(pruned.Neurons, neuronID)
+		}
+	}
+	// Synapses only carry signals when their src fires. Even a kept MOTOR that
+	// never fires loses its synapses, since having no input keeps it from
+	// firing just the same.
+	for synID, syn := range pruned.Synpases.idMap {
+		_, srcLive := live[syn.src]
+		_, dstLive := live[syn.dst]
+		if !srcLive || !dstLive {
+			pruned.RemoveSynapse(synID)
+		}
+	}
+
+	useful := pruned.reachesMotor()
+	for neuronID := range pruned.Neurons {
+		if _, ok := useful[neuronID]; !ok && !pruned.isSenseOrMotor(neuronID) {
+			delete(pruned.Neurons, neuronID)
+		}
+	}
+	for synID, syn := range pruned.Synpases.idMap {
+		_, srcOK := pruned.Neurons[syn.src]
+		_, dstOK := pruned.Neurons[syn.dst]
+		if !srcOK || !dstOK {
+			pruned.RemoveSynapse(synID)
+		}
+	}
+
+	pruned.foldConstants()
+	return pruned
+}
+
+func (d *DNA[S]) isSenseOrMotor(neuronID IDType) bool {
+	return d.Source.NeuronIDs[SENSE].HasID(neuronID) || d.Source.NeuronIDs[MOTOR].HasID(neuronID)
+}
+
+// liveNeurons returns every neuron that can possibly fire. SENSE neurons
+// always can, and other neurons need a signal from a live neuron, or from
+// every one of their sources when they wait on AllInputs.
+func (d *DNA[S]) liveNeurons() IDSet {
+	sources := make(map[IDType][]IDType, len(d.Neurons))
+	for _, syn := range d.Synpases.idMap {
+		sources[syn.dst] = append(sources[syn.dst], syn.src)
+	}
+
+	live := make(IDSet, len(d.Neurons))
+	for index := 0; index < d.Source.NeuronIDs[SENSE].Length(); index++ {
+		live[d.Source.NeuronIDs[SENSE].GetID(index)] = member
+	}
+
+	// Keep going until nothing changes, since every neuron that comes alive
+	// can bring others along with it.
+	for changed := true; changed; {
+		changed = false
+		for neuronID, neuron := range d.Neurons {
+			if _, ok := live[neuronID]; ok || len(sources[neuronID]) == 0 {
+				continue
+			}
+
+			numLive := 0
+			for _, src := range sources[neuronID] {
+				if _, ok := live[src]; ok {
+					numLive++
+				}
+			}
+			if (neuron.Threshold == AllInputs && numLive == len(sources[neuronID])) ||
+				(neuron.Threshold != AllInputs && numLive > 0) {
+				live[neuronID] = member
+				changed = true
+			}
+		}
+	}
+	return live
+}
+
+// reachesMotor returns every neuron with a path to a MOTOR neuron, including
+// the MOTOR neurons themselves.
+func (d *DNA[S]) reachesMotor() IDSet {
+	srcs := make(map[IDType][]IDType, len(d.Neurons))
+	for _, syn := range d.Synpases.idMap {
+		srcs[syn.dst] = append(srcs[syn.dst], syn.src)
+	}
+
+	useful := make(IDSet, len(d.Neurons))
+	queue := make([]IDType, 0)
+	for index := 0; index < d.Source.NeuronIDs[MOTOR].Length(); index++ {
+		motorID := d.Source.NeuronIDs[MOTOR].GetID(index)
+		useful[motorID] = member
+		queue = append(queue, motorID)
+	}
+	for len(queue) > 0 {
+		neuronID := queue[0]
+		queue = queue[1:]
+		for _, src := range srcs[neuronID] {
+			if _, ok := useful[src]; !ok {
+				useful[src] = member
+				queue = append(queue, src)
+			}
+		}
+	}
+	return useful
+}
+
+// foldConstants turns PURE neurons that always give 0 or the max into
+// FALSIFY or TRUTH neurons. That happens when a seed absorbs every other
+// input, or when an idempotent op only gets signals from neurons that give
+// the same constant. Seeds are left alone since they still count towards the
+// threshold.
+func (d *DNA[S]) foldConstants() {
+	w := WidthOf[S]()
+	sources := make(map[IDType][]IDType, len(d.Neurons))
+	for _, syn := range d.Synpases.idMap {
+		sources[syn.dst] = append(sources[syn.dst], syn.src)
+	}
+
+	// constant holds whether each folded neuron always gives the max.
+	constant := make(map[IDType]bool)
+	for neuronID, neuron := range d.Neurons {
+		if neuron.Kind != PURE {
+			continue
+		}
+		switch neuron.Op {
+		case TRUTH:
+			constant[neuronID] = true
+		case FALSIFY:
+			constant[neuronID] = false
+		}
+		if absorb, ok := absorbingSeeds[neuron.Op]; ok && neuron.HasSeed && isExtreme(Word(neuron.Seed), absorb[0], w) {
+			constant[neuronID] = absorb[1]
+		}
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for neuronID, neuron := range d.Neurons {
+			if _, ok := constant[neuronID]; ok || neuron.Kind != PURE || !idempotentOps[neuron.Op] {
+				continue
+			}
+			if len(sources[neuronID]) == 0 || d.Source.NeuronIDs[SENSE].HasID(neuronID) {
+				continue
+			}
+
+			value, same := constant[sources[neuronID][0]]
+			for _, src := range sources[neuronID] {
+				if srcValue, ok := constant[src]; !ok || srcValue != value {
+					same = false
+				}
+			}
+			if same && (!neuron.HasSeed || isExtreme(Word(neuron.Seed), value, w)) {
+				constant[neuronID] = value
+				changed = true
+			}
+		}
+	}
+
+	for neuronID, isMax := range constant {
+		if isMax {
+			d.Neurons[neuronID].Op = TRUTH
+		} else {
+			d.Neurons[neuronID].Op = FALSIFY
+		}
+	}
+}
+
+// isExtreme returns true if the value is the max signal when isMax is true,
+// or 0 when it's false.
+func isExtreme(x Word, isMax bool, w Width) bool {
+	if isMax {
+		return x == w.Max()
+	}
+	return x == 0
+}
@@ -0,0 +1,127 @@
+package neuron
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPrune(t *testing.T) {
+	c := NewConglomerate()
+	c.AddVisionAndMotor(1, 1)                 // N0 -> N1 on Syn0
+	deadEnd := c.AddInterNeuron(0)            // N2 with Syn1 and Syn2
+	unreachable := c.AddInterNeuron(2)        // N3 on Syn2, with Syn3 and Syn4
+	loop := c.Synapses.AddNewSynapse(1, 3)    // Syn5, which MOTOR neurons don't normally have
+	waiting := c.AddInterNeuron(0)            // N4 with Syn6 and Syn7
+	waitSyn := c.Synapses.AddNewSynapse(3, 4) // Syn8
+
+	d := NewDNA[SignalType](c)
+	for neuronID := 0; neuronID <= waiting; neuronID++ {
+		d.AddNeuron(neuronID, OR)
+	}
+	// N2 only receives from the vision neuron, so it fires but can't reach the
+	// motor.
+	d.AddSynapse(0)
+	d.AddSynapse(1)
+	// N3 has no synapse in, and N4 waits on all of its inputs, including N3.
+	d.AddSynapse(4)
+	d.AddSynapse(6)
+	d.AddSynapse(7)
+	d.AddSynapse(waitSyn)
+	d.Neurons[waiting].Threshold = AllInputs
+	_ = loop
+
+	pruned := d.Prune()
+	for _, neuronID := range []IDType{deadEnd, unreachable, waiting} {
+		if _, ok := pruned.Neurons[neuronID]; ok {
+			t.Errorf("Neuron %d should be pruned:\n%s", neuronID, pruned.PrettyPrint())
+		}
+	}
+	if got, want := pruned.PrettyPrint(), "0 (V0) = op2 [1]\n1 (M0) = op2\n"; got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+	// The original is untouched.
+	if got, want := len(d.Neurons), 5; got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+}
+
+func TestPruneDeadMotor(t *testing.T) {
+	d := SimpleTestDNA()
+	c := d.Source
+	inter := c.AddInterNeuron(1)
+	d.AddNeuron(inter, OR)
+	d.AddSynapse(c.Synapses.nextID - 1)
+	// The motor waits on a neuron that never gets a signal.
+	d.Neurons[2].Threshold = AllInputs
+
+	pruned := d.Prune()
+	if _, ok := pruned.Neurons[inter]; ok {
+		t.Errorf("Neuron %d should be pruned", inter)
+	}
+	if got, want := len(pruned.Synpases.InputSlots(2)), 0; got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+	inputs := [][]SignalType{{1}, {2}}
+	if got, want := Flourish(pruned).Fire(inputs), Flourish(d).Fire(inputs); !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+}
+
+func TestFoldConstants(t *testing.T) {
+	c := NewConglomerate()
+	c.AddVisionAndMotor(1, 1)
+	first := c.AddInterNeuron(0)
+	second := c.AddInterNeuron(c.Synapses.nextID - 1)
+
+	d := NewDNA[SignalType](c)
+	d.AddNeuron(0, OR)
+	d.AddNeuron(1, ADD)
+	d.AddNeuron(first, AND)
+	d.SetSeed(first, 0)
+	d.AddNeuron(second, OR)
+	for synID, syn := range c.Synapses.idMap {
+		if syn.src != 0 || syn.dst != 1 {
+			d.AddSynapse(synID)
+		}
+	}
+	// Only go through the chain of inter neurons.
+	d.RemoveSynapse(2)
+
+	pruned := d.Prune()
+	// The AND is absorbed by its seed, and the OR only gets that constant.
+	if got, want := pruned.Neurons[first].Op, FALSIFY; got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+	if got, want := pruned.Neurons[second].Op, FALSIFY; got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+	if !pruned.Neurons[first].HasSeed {
+		t.Errorf("Seed should be kept for the threshold")
+	}
+	// Adding up the constants depends on how many there are.
+	if got, want := pruned.Neurons[1].Op, ADD; got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+}
+
+func TestPruneMatchesOriginal(t *testing.T) {
+	seed := time.Now().UnixNano()
+	rnd := rand.New(rand.NewSource(seed))
+	for trial := 0; trial < 30; trial++ {
+		dna := randomTestDNA(rnd)
+		pruned := dna.Prune()
+		if len(pruned.Neurons) > len(dna.Neurons) {
+			t.Errorf("Pruning added neurons")
+		}
+
+		brain, prunedBrain := Flourish(dna), Flourish(pruned)
+		for fire := 0; fire < 6; fire++ {
+			inputs := randomTestInputs(rnd, dna.Source.NeuronIDs[SENSE].Length())
+			if got, want := prunedBrain.Fire(inputs), brain.Fire(inputs); !reflect.DeepEqual(got, want) {
+				t.Fatalf("Seed %d, trial %d, fire %d: inputs %v got %v, want %v for:\n%s\npruned to:\n%s", seed, trial, fire, inputs, got, want, dna.PrettyPrint(), pruned.PrettyPrint())
+			}
+		}
+	}
+}
@@ -10,13 +10,13 @@ import (
 type ScoreType int64
 
 // Game defines the methods needed to simulate a game.
-type Game interface {
+type Game[S Signal] interface {
 	// CurrentState is the state of the game represented by a series of signals.
-	// In the future this should return a proto.Message
-	CurrentState() [][]SignalType
+	// The encode package can build them from a struct or a proto.Message.
+	CurrentState() [][]S
 
 	// Update changes the game state based on a series of moves.
-	Update(signals [][]SignalType)
+	Update(signals [][]S)
 
 	IsOver() bool
 
@@ -24,31 +24,104 @@ type Game interface {
 	Fitness() ScoreType
 }
 
+// StepBudgeter is an optional interface for games that need a different step
+// budget than the one in the RunnerConfig.
+type StepBudgeter interface {
+	StepBudget() int
+}
+
 // NewGameFunc does any setup necessary to begin playing. Essentially a
 // factory method/constructor to generate new games.
-type NewGameFunc func() Game
+type NewGameFunc[S Signal] func() Game[S]
+
+// CostConfig puts a price on the size and activity of a brain, which is taken
+// out of its fitness so that selection favors small, cheap networks. Every cost
+// defaults to 0, which leaves the fitness as is.
+type CostConfig struct {
+	// Neuron and Synapse are charged once per game for each one in the DNA.
+	Neuron  float32
+	Synapse float32
+	// Ops are charged on top of the Neuron cost for each neuron using the op.
+	Ops map[OperatorType]float32
+
+	// Firing is charged each time a neuron fires during the game.
+	Firing float32
+}
 
-type RunnerConfig struct {
+// penalty is the total cost of playing one game with the DNA.
+func (c CostConfig) penalty(genomeCost float32, firings int) ScoreType {
+	return ScoreType(math.Round(float64(genomeCost + c.Firing*float32(firings))))
+}
+
+type RunnerConfig[S Signal] struct {
 	Generations int
 	Rounds      int
-	NewGameFn   NewGameFunc
+	NewGameFn   NewGameFunc[S]
+
+	// StepBudget is the most steps a brain takes for each move, or the
+	// DefaultStepBudget when it's 0. Games can override it with StepBudgeter.
+	StepBudget int
+	// Carry is what brains keep from one move to the next. Games can override
+	// it with Carrier.
+	Carry CarryPolicy
+
+	// Costs are taken out of the game's fitness, and the result is what
+	// evolution and each generation's winner go by. Only the check for a
+	// perfect game, which ends the run, uses the raw fitness.
+	Costs CostConfig
+
+	// CacheFitness skips playing a game when a genome with the same Hash has
+	// already played it, and uses that fitness instead. This is only right for
+	// games that always give the same fitness to the same brain. It's turned
+	// off while firings are charged, since dead structure that's left out of
+	// the hash still fires.
+	CacheFitness bool
+
+	// HallOfFame is the number of the best DNA to keep over every generation,
+	// for Runner.HallOfFame. It's 0 when there's no hall of fame.
+	HallOfFame int
 
 	PConf PlaygroundConfig
 }
 
-type Runner struct {
-	config RunnerConfig
-	play   *Playground
+type Runner[S Signal] struct {
+	config RunnerConfig[S]
+	play   *Playground[S]
+	// lastWinner is the winner of the last generation, to show what changed
+	// in the next winner. It isn't pruned, since pruning folds constants into
+	// op and seed changes that evolution never made.
+	lastWinner *DNA[S]
+	cache      *fitnessCache
+	// ranked are the DNA of the last generation, best first.
+	ranked     []EnsembleMember[S]
+	hallOfFame *HallOfFame[S]
 }
 
-func NewRunner(config RunnerConfig) *Runner {
-	return &Runner{
+func NewRunner[S Signal](config RunnerConfig[S]) *Runner[S] {
+	r := &Runner[S]{
 		config: config,
-		play:   NewPlayground(config.PConf),
+		play:   NewPlayground[S](config.PConf),
+		cache:  newFitnessCache(),
+	}
+	if config.HallOfFame > 0 {
+		r.hallOfFame = NewHallOfFame[S](config.HallOfFame)
 	}
+	return r
 }
 
-func (r *Runner) Run() {
+// CacheStats are the fitness cache stats over every generation so far.
+func (r *Runner[S]) CacheStats() FitnessCacheStats {
+	r.cache.mu.Lock()
+	defer r.cache.mu.Unlock()
+	return r.cache.total
+}
+
+// cachingFitness returns true if the fitness cache is on.
+func (r *Runner[S]) cachingFitness() bool {
+	return r.config.CacheFitness && r.config.Costs.Firing == 0
+}
+
+func (r *Runner[S]) Run() {
 	fmt.Printf("Beginning run with config: %+v\n", r.config)
 	r.play.InitDNA()
 	for gen := 0; gen < r.config.Generations; gen++ {
@@ -63,7 +136,7 @@ func (r *Runner) Run() {
 	fmt.Printf("Never found a winner :/\nDynamo result: %d\n", dynScore)
 }
 
-func (r *Runner) runGeneration(gen int) bool {
+func (r *Runner[S]) runGeneration(gen int) bool {
 	results := make([]BrainScore, r.play.config.NumVariants)
 
 	resChan := make(chan BrainScore)
@@ -78,8 +151,13 @@ func (r *Runner) runGeneration(gen int) bool {
 			result := <-resChan
 			results[result.id].id = result.id
 			results[result.id].score += result.score
+			results[result.id].raw += result.raw
 		}
 	}
+	if r.cachingFitness() {
+		stats := r.cache.endGeneration()
+		fmt.Printf("Fitness cache: %d hits, %d misses (%.1f%% hit rate), %d genomes\n", stats.Hits, stats.Misses, 100*stats.HitRate(), stats.Entries)
+	}
 
 	// If the max possible score has been reached, the simulation can end.
 	maxResult := BrainScore{
@@ -92,11 +170,26 @@ func (r *Runner) runGeneration(gen int) bool {
 		}
 	}
 	bestDNA := r.play.codes[maxResult.id]
-	fmt.Printf("Winner of generation:\n%sEnded with %d score\n\n", bestDNA.PrettyPrint(), maxResult.score)
+	// Only print the parts of the winner that make a difference.
+	winner := bestDNA.Prune()
+	fmt.Printf("Winner of generation:\n%sEnded with %d score (%d before costs)\n\n", winner.PrettyPrint(), maxResult.score, maxResult.raw)
+	if r.lastWinner != nil {
+		fmt.Printf("Changed from the last winner:\n%s\n", r.lastWinner.Diff(bestDNA).Text())
+	}
+	r.lastWinner = bestDNA.DeepCopy()
+	r.rank(results)
 
-	// For roman numerals: r.config.Rounds*256*256*7
-	// For the healthchecker: r.config.Rounds*86400
-	if maxResult.score == ScoreType(r.config.Rounds*256*256) { // For the adder.
+	// Costs can make a perfect brain score lower than a cheaper imperfect one,
+	// so check everyone's raw fitness for a perfect game.
+	won := false
+	for _, result := range results {
+		// For roman numerals: r.config.Rounds*256*256*7
+		// For the healthchecker: r.config.Rounds*86400
+		if result.raw == ScoreType(r.config.Rounds*256*256) { // For the adder.
+			won = true
+		}
+	}
+	if won {
 		dynScore := gen * r.config.Rounds * r.play.config.NumVariants
 		dynamo.Record("evolve", dynScore)
 		fmt.Printf("We have a winner!\nDynamo result: %d\n", dynScore)
@@ -107,16 +200,49 @@ func (r *Runner) runGeneration(gen int) bool {
 	return false
 }
 
-func (r *Runner) gameSimulation(id IDType, resChan chan BrainScore) {
-	game := r.config.NewGameFn()
-	brain := r.play.GetBrain(id)
+func (r *Runner[S]) gameSimulation(id IDType, resChan chan BrainScore) {
+	dna := r.play.codes[id]
+	var raw ScoreType
+	firings := 0
+	if r.cachingFitness() {
+		hash := dna.Hash()
+		var ok bool
+		if raw, ok = r.cache.get(hash); !ok {
+			raw, firings = r.playGame(dna)
+			r.cache.put(hash, raw)
+		}
+	} else {
+		raw, firings = r.playGame(dna)
+	}
 
-	for !game.IsOver() {
-		game.Update(brain.Fire(game.CurrentState()))
+	score := raw - r.config.Costs.penalty(dna.Cost(r.config.Costs), firings)
+	// Costs never turn a non-negative fitness negative, since offspring are
+	// handed out in proportion to the scores.
+	if raw >= 0 && score < 0 {
+		score = 0
 	}
 
 	resChan <- BrainScore{
 		id:    id,
-		score: game.Fitness(),
+		score: score,
+		raw:   raw,
 	}
 }
+
+// playGame plays a new game with the DNA, and returns the fitness along with
+// how many times the neurons fired.
+func (r *Runner[S]) playGame(dna *DNA[S]) (ScoreType, int) {
+	game := r.config.NewGameFn()
+	// The compiled brain fires the same as GetBrain, only faster.
+	brain := Compile(dna).NewBrain()
+	brain.SetStepBudget(r.config.StepBudget)
+	if budgeter, ok := game.(StepBudgeter); ok {
+		brain.SetStepBudget(budgeter.StepBudget())
+	}
+	brain.SetCarryPolicy(r.config.Carry)
+	if carrier, ok := game.(Carrier); ok {
+		brain.SetCarryPolicy(carrier.CarryPolicy())
+	}
+
+	return Play[S](game, brain), brain.Firings()
+}
@@ -29,11 +29,11 @@ func (t *testGame) Fitness() ScoreType {
 	return t.score
 }
 
-func createTestRunner() *Runner {
-	return NewRunner(RunnerConfig{
+func createTestRunner() *Runner[SignalType] {
+	return NewRunner(RunnerConfig[SignalType]{
 		Generations: 3,
 		Rounds:      2,
-		NewGameFn: func() Game {
+		NewGameFn: func() Game[SignalType] {
 			return &testGame{
 				turn:  1,
 				score: 0,
@@ -47,6 +47,16 @@ func TestRunGeneration(t *testing.T) {
 	runner := createTestRunner()
 	runner.play.InitDNA()
 	runner.runGeneration(0)
+
+	// The next winner is compared against the whole of this one, rather than
+	// its pruned form.
+	best := runner.TopMembers(1)[0].DNA
+	if got, want := runner.lastWinner.PrettyPrint(), best.PrettyPrint(); got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+	if runner.lastWinner == best {
+		t.Errorf("The last winner should be a copy")
+	}
 }
 
 func TestGameSim(t *testing.T) {
@@ -61,8 +71,72 @@ func TestGameSim(t *testing.T) {
 	expected := BrainScore{
 		id:    0,
 		score: 18,
+		raw:   18,
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Got %+v, want %+v", result, expected)
+	}
+}
+
+func TestGameSimCosts(t *testing.T) {
+	runner := createTestRunner()
+	runner.config.Costs = CostConfig{
+		Neuron:  1,
+		Synapse: 1,
+	}
+	runner.play.InitDNA()
+	runner.play.codes[0] = SimpleTestDNA()
+
+	resChan := make(chan BrainScore)
+	go runner.gameSimulation(0, resChan)
+
+	// Three neurons and two synapses.
+	result := <-resChan
+	expected := BrainScore{
+		id:    0,
+		score: 13,
+		raw:   18,
 	}
 	if !reflect.DeepEqual(result, expected) {
 		t.Errorf("Got %+v, want %+v", result, expected)
 	}
+
+	// The cost of firing can't take the score below zero.
+	runner.config.Costs = CostConfig{Firing: 100}
+	go runner.gameSimulation(0, resChan)
+	if got, want := (<-resChan).score, ScoreType(0); got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+}
+
+type budgetGame struct {
+	testGame
+	budget int
+}
+
+func (b *budgetGame) StepBudget() int {
+	return b.budget
+}
+
+func TestGameSimStepBudget(t *testing.T) {
+	runner := createTestRunner()
+	runner.play.InitDNA()
+	runner.play.codes[0] = SimpleTestDNA()
+
+	// Two steps are one short of the output being terminated.
+	runner.config.StepBudget = 2
+	resChan := make(chan BrainScore)
+	go runner.gameSimulation(0, resChan)
+	if got, want := (<-resChan).raw, ScoreType(0); got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+
+	// The game's own budget wins over the config.
+	runner.config.NewGameFn = func() Game[SignalType] {
+		return &budgetGame{testGame: testGame{turn: 1}, budget: 3}
+	}
+	go runner.gameSimulation(0, resChan)
+	if got, want := (<-resChan).raw, ScoreType(18); got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
 }
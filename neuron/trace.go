@@ -0,0 +1,140 @@
+package neuron
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Tracer is told what happened during every step of a brain that's firing,
+// which is how to see why a brain gives the output it does.
+type Tracer[S Signal] interface {
+	TraceStep(step StepTrace[S])
+}
+
+// FiringTrace is one neuron firing during a step.
+type FiringTrace[S Signal] struct {
+	Neuron IDType
+	// Inputs are the ones the neuron operated on, in input slot order with the
+	// seed last. Any past the op's MaxInputs are left out.
+	Inputs []S
+	Output S
+}
+
+// StepTrace is everything that happened during a single step.
+type StepTrace[S Signal] struct {
+	// Step counts every step since the brain was flourished.
+	Step int
+	// Fired is in neuron ID order.
+	Fired []FiringTrace[S]
+	// Terminated are the indices of MOTOR neurons whose output was terminated
+	// during the step.
+	Terminated []int
+	// Pending are the signals left over for the next step, in input slot
+	// order.
+	Pending map[IDType][]S
+}
+
+// SetTracer starts sending a StepTrace to the tracer after every step, or
+// stops tracing if it's nil. Tracing is off by default since it's costly.
+func (b *Brain[S]) SetTracer(tracer Tracer[S]) {
+	b.tracer = tracer
+}
+
+// TraceRecorder is a Tracer that holds on to every step.
+type TraceRecorder[S Signal] struct {
+	Steps []StepTrace[S]
+}
+
+// NewTraceRecorder inits an empty recorder.
+func NewTraceRecorder[S Signal]() *TraceRecorder[S] {
+	return &TraceRecorder[S]{
+		Steps: make([]StepTrace[S], 0),
+	}
+}
+
+// TraceStep records the step.
+func (r *TraceRecorder[S]) TraceStep(step StepTrace[S]) {
+	r.Steps = append(r.Steps, step)
+}
+
+// Signals are written as plain numbers, since a []uint8 would otherwise be
+// written as base64.
+type firingJSON struct {
+	Neuron IDType `json:"neuron"`
+	Inputs []Word `json:"inputs"`
+	Output Word   `json:"output"`
+}
+
+type stepJSON struct {
+	Step       int               `json:"step"`
+	Fired      []firingJSON      `json:"fired"`
+	Terminated []int             `json:"terminated,omitempty"`
+	Pending    map[IDType][]Word `json:"pending,omitempty"`
+}
+
+func toWords[S Signal](sigs []S) []Word {
+	words := make([]Word, len(sigs))
+	for i, sig := range sigs {
+		words[i] = Word(sig)
+	}
+	return words
+}
+
+// WriteJSONLines writes every recorded step as a JSON object on its own line.
+func (r *TraceRecorder[S]) WriteJSONLines(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	for _, step := range r.Steps {
+		out := stepJSON{
+			Step:       step.Step,
+			Fired:      make([]firingJSON, len(step.Fired)),
+			Terminated: step.Terminated,
+			Pending:    make(map[IDType][]Word, len(step.Pending)),
+		}
+		for i, firing := range step.Fired {
+			out.Fired[i] = firingJSON{
+				Neuron: firing.Neuron,
+				Inputs: toWords(firing.Inputs),
+				Output: Word(firing.Output),
+			}
+		}
+		for neuronID, sigs := range step.Pending {
+			out.Pending[neuronID] = toWords(sigs)
+		}
+		if err := encoder.Encode(out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Timeline returns a step by step description of the recording, like:
+//
+//	step 3
+//	  fired 2 [1 2] -> 3
+//	  terminated M0
+//	  pending 2 [0 0]
+func (r *TraceRecorder[S]) Timeline() string {
+	var sb strings.Builder
+	for _, step := range r.Steps {
+		sb.WriteString(fmt.Sprintf("step %d\n", step.Step))
+		for _, firing := range step.Fired {
+			sb.WriteString(fmt.Sprintf("  fired %d %v -> %d\n", firing.Neuron, firing.Inputs, firing.Output))
+		}
+		for _, motorIndex := range step.Terminated {
+			sb.WriteString(fmt.Sprintf("  terminated M%d\n", motorIndex))
+		}
+
+		pendingIDs := make([]IDType, 0, len(step.Pending))
+		for neuronID := range step.Pending {
+			pendingIDs = append(pendingIDs, neuronID)
+		}
+		sort.Ints(pendingIDs)
+		for _, neuronID := range pendingIDs {
+			sb.WriteString(fmt.Sprintf("  pending %d %v\n", neuronID, step.Pending[neuronID]))
+		}
+	}
+	return sb.String()
+}
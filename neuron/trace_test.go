@@ -0,0 +1,88 @@
+package neuron
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTraceRecorder(t *testing.T) {
+	b := Flourish(SimpleTestDNA())
+	recorder := NewTraceRecorder[SignalType]()
+	b.SetTracer(recorder)
+	b.Fire([][]SignalType{{1}, {2}})
+
+	want := []StepTrace[SignalType]{
+		{
+			Step: 0,
+			Fired: []FiringTrace[SignalType]{
+				{Neuron: 0, Inputs: []SignalType{1, 0}, Output: 1},
+				{Neuron: 1, Inputs: []SignalType{2, 0}, Output: 2},
+			},
+			Pending: map[IDType][]SignalType{2: {1, 2}},
+		},
+		{
+			Step: 1,
+			Fired: []FiringTrace[SignalType]{
+				{Neuron: 0, Inputs: []SignalType{0, 0}, Output: 0},
+				{Neuron: 1, Inputs: []SignalType{0, 0}, Output: 0},
+				{Neuron: 2, Inputs: []SignalType{1, 2}, Output: 3},
+			},
+			Pending: map[IDType][]SignalType{2: {0, 0}},
+		},
+		{
+			Step: 2,
+			Fired: []FiringTrace[SignalType]{
+				{Neuron: 2, Inputs: []SignalType{0, 0}, Output: 0},
+			},
+			Terminated: []int{0},
+			Pending:    map[IDType][]SignalType{},
+		},
+	}
+	if !reflect.DeepEqual(recorder.Steps, want) {
+		t.Errorf("Got %+v, want %+v", recorder.Steps, want)
+	}
+
+	timeline := recorder.Timeline()
+	for _, line := range []string{"step 1\n", "  fired 2 [1 2] -> 3\n", "  terminated M0\n", "  pending 2 [0 0]\n"} {
+		if !strings.Contains(timeline, line) {
+			t.Errorf("Want %q in:\n%s", line, timeline)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := recorder.WriteJSONLines(&buf); err != nil {
+		t.Fatalf("Got error %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if got, want := len(lines), 3; got != want {
+		t.Fatalf("Got %v, want %v", got, want)
+	}
+	if got, want := lines[2], `{"step":2,"fired":[{"neuron":2,"inputs":[0,0],"output":0}],"terminated":[0]}`; got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+
+	// Turning off the tracer stops the recording.
+	b.SetTracer(nil)
+	b.Fire([][]SignalType{{1}, {2}})
+	if got, want := len(recorder.Steps), 3; got != want {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+}
+
+func TestTraceMaxInputsWithSeed(t *testing.T) {
+	dna := SimpleTestDNA()
+	dna.Neurons[2].Op = AVERAGE
+	dna.SetSeed(2, 100)
+	b := Flourish(dna)
+	recorder := NewTraceRecorder[SignalType]()
+	b.SetTracer(recorder)
+	b.Fire([][]SignalType{{10}, {20}})
+
+	// AVERAGE only takes two inputs, so the seed takes the place of the second.
+	want := FiringTrace[SignalType]{Neuron: 2, Inputs: []SignalType{10, 100}, Output: 55}
+	if got := recorder.Steps[1].Fired[2]; !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,259 @@
+package neuron
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Invariant is an enum for the assumptions the rest of the code makes about
+// conglomerates and DNA.
+type Invariant int
+
+const (
+	// INDEX_MISMATCH is an IndexedIDs whose two maps don't agree, or whose
+	// indices aren't 0 to N.
+	INDEX_MISMATCH Invariant = iota
+	// DUPLICATE_NEURON is a neuron ID tracked under more than one type.
+	DUPLICATE_NEURON
+	// SRC_MAP_MISMATCH is a SynapseTracker whose srcMap doesn't match its
+	// idMap.
+	SRC_MAP_MISMATCH
+	// STALE_NEXT_ID is a next ID that's already in use, so it would be handed
+	// out twice.
+	STALE_NEXT_ID
+	// UNKNOWN_ENDPOINT is a synapse to or from a neuron that doesn't exist.
+	UNKNOWN_ENDPOINT
+	// UNKNOWN_NEURON is a DNA neuron that isn't in the conglomerate.
+	UNKNOWN_NEURON
+	// SOURCE_MISMATCH is a DNA synapse that isn't in the conglomerate, or
+	// connects different neurons there.
+	SOURCE_MISMATCH
+	// MISSING_IO is a SENSE or MOTOR neuron that's missing from the DNA.
+	MISSING_IO
+	// INVALID_NEURON is a neuron that's nil or uses an unregistered op.
+	INVALID_NEURON
+)
+
+func (i Invariant) String() string {
+	return [...]string{"INDEX_MISMATCH", "DUPLICATE_NEURON", "SRC_MAP_MISMATCH", "STALE_NEXT_ID", "UNKNOWN_ENDPOINT", "UNKNOWN_NEURON", "SOURCE_MISMATCH", "MISSING_IO", "INVALID_NEURON"}[i]
+}
+
+// ValidationError is a single broken invariant.
+type ValidationError struct {
+	Invariant Invariant
+	// ID is the neuron or synapse the invariant is broken on.
+	ID     IDType
+	Detail string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%v: %s", e.Invariant, e.Detail)
+}
+
+// ValidationErrors is every invariant that Validate found broken, in a stable
+// order.
+type ValidationErrors []*ValidationError
+
+func (errs ValidationErrors) Error() string {
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Has returns true if any of the errors broke the invariant.
+func (errs ValidationErrors) Has(invariant Invariant) bool {
+	for _, err := range errs {
+		if err.Invariant == invariant {
+			return true
+		}
+	}
+	return false
+}
+
+// validator collects the errors so each check can keep going after the first
+// one.
+type validator struct {
+	errs ValidationErrors
+}
+
+func (v *validator) fail(invariant Invariant, id IDType, format string, args ...interface{}) {
+	v.errs = append(v.errs, &ValidationError{
+		Invariant: invariant,
+		ID:        id,
+		Detail:    fmt.Sprintf(format, args...),
+	})
+}
+
+// result returns nil when nothing failed, so callers can check for a nil
+// error as usual.
+func (v *validator) result() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
+
+// Validate checks that the IDs of each neuron type agree with each other,
+// that every synapse is tracked consistently and connects known neurons, and
+// that the next IDs haven't been used yet. It returns ValidationErrors, or nil
+// if every invariant holds.
+func (c *Conglomerate) Validate() error {
+	v := &validator{}
+	c.validate(v)
+	return v.result()
+}
+
+func (c *Conglomerate) validate(v *validator) {
+	seen := make(map[IDType]NeuronType)
+	for _, nType := range NeuronTypes {
+		ids := c.NeuronIDs[nType]
+		if ids == nil {
+			v.fail(INDEX_MISMATCH, -1, "no %v neuron IDs", nType)
+			continue
+		}
+		ids.validate(v, nType)
+
+		for _, id := range sortedIndexedIDs(ids) {
+			if other, ok := seen[id]; ok {
+				v.fail(DUPLICATE_NEURON, id, "neuron %d is both %v and %v", id, other, nType)
+			}
+			seen[id] = nType
+			if id >= c.nextNeuronID {
+				v.fail(STALE_NEXT_ID, id, "neuron %d isn't below the next neuron ID %d", id, c.nextNeuronID)
+			}
+		}
+	}
+
+	c.Synapses.validate(v)
+	for _, synID := range sortedSynapseIDs(c.Synapses) {
+		syn := c.Synapses.idMap[synID]
+		for _, end := range []IDType{syn.src, syn.dst} {
+			if _, ok := seen[end]; !ok {
+				v.fail(UNKNOWN_ENDPOINT, synID, "synapse %d connects unknown neuron %d", synID, end)
+			}
+		}
+	}
+}
+
+func (x *IndexedIDs) validate(v *validator, nType NeuronType) {
+	if len(x.IDToIndex) != len(x.IndexToID) {
+		v.fail(INDEX_MISMATCH, -1, "%v has %d IDs but %d indices", nType, len(x.IDToIndex), len(x.IndexToID))
+	}
+	for _, id := range sortedIndexedIDs(x) {
+		index := x.IDToIndex[id]
+		if index < 0 || index >= len(x.IDToIndex) {
+			v.fail(INDEX_MISMATCH, id, "%v neuron %d has index %d outside of 0 to %d", nType, id, index, len(x.IDToIndex)-1)
+		}
+		if got, ok := x.IndexToID[index]; !ok || got != id {
+			v.fail(INDEX_MISMATCH, id, "%v neuron %d has index %d, which maps back to %d", nType, id, index, got)
+		}
+	}
+}
+
+func (s *SynapseTracker) validate(v *validator) {
+	for _, synID := range sortedSynapseIDs(s) {
+		syn := s.idMap[synID]
+		if _, ok := s.srcMap[syn.src][synID]; !ok {
+			v.fail(SRC_MAP_MISMATCH, synID, "synapse %d is missing from the srcMap of neuron %d", synID, syn.src)
+		}
+		if synID >= s.nextID {
+			v.fail(STALE_NEXT_ID, synID, "synapse %d isn't below the next synapse ID %d", synID, s.nextID)
+		}
+	}
+
+	srcs := make([]IDType, 0, len(s.srcMap))
+	for src := range s.srcMap {
+		srcs = append(srcs, src)
+	}
+	sort.Ints(srcs)
+	for _, src := range srcs {
+		synIDs := make([]IDType, 0, len(s.srcMap[src]))
+		for synID := range s.srcMap[src] {
+			synIDs = append(synIDs, synID)
+		}
+		sort.Ints(synIDs)
+		for _, synID := range synIDs {
+			if syn, ok := s.idMap[synID]; !ok || syn.src != src {
+				v.fail(SRC_MAP_MISMATCH, synID, "srcMap of neuron %d has synapse %d, which isn't from it", src, synID)
+			}
+		}
+	}
+}
+
+// Validate checks the DNA against its Source, which must be valid too. Every
+// DNA neuron must be in the Source along with every SENSE and MOTOR neuron,
+// every DNA synapse must match the Source and connect neurons that the DNA
+// has, and every neuron must use a registered op. It returns
+// ValidationErrors, or nil if every invariant holds.
+func (d *DNA[S]) Validate() error {
+	v := &validator{}
+	if d.Source == nil {
+		v.fail(UNKNOWN_NEURON, -1, "DNA has no source")
+		return v.result()
+	}
+	d.Source.validate(v)
+	for _, nType := range NeuronTypes {
+		// The rest of the checks need the IDs of every type.
+		if d.Source.NeuronIDs[nType] == nil {
+			return v.result()
+		}
+	}
+	d.Synpases.validate(v)
+
+	for _, neuronID := range sortedNeuronIDs(d) {
+		neuron := d.Neurons[neuronID]
+		if !d.Source.hasNeuron(neuronID) {
+			v.fail(UNKNOWN_NEURON, neuronID, "neuron %d isn't in the source", neuronID)
+		}
+		if neuron == nil {
+			v.fail(INVALID_NEURON, neuronID, "neuron %d is nil", neuronID)
+		} else if neuron.Op < 0 || int(neuron.Op) >= NumOps() {
+			v.fail(INVALID_NEURON, neuronID, "neuron %d has unregistered op %d", neuronID, neuron.Op)
+		}
+	}
+
+	for _, nType := range []NeuronType{SENSE, MOTOR} {
+		for index := 0; index < d.Source.NeuronIDs[nType].Length(); index++ {
+			neuronID := d.Source.NeuronIDs[nType].GetID(index)
+			if _, ok := d.Neurons[neuronID]; !ok {
+				v.fail(MISSING_IO, neuronID, "%v neuron %d is missing", nType, neuronID)
+			}
+		}
+	}
+
+	for _, synID := range sortedSynapseIDs(d.Synpases) {
+		syn := d.Synpases.idMap[synID]
+		if sourceSyn, ok := d.Source.Synapses.idMap[synID]; !ok {
+			v.fail(SOURCE_MISMATCH, synID, "synapse %d isn't in the source", synID)
+		} else if sourceSyn != syn {
+			v.fail(SOURCE_MISMATCH, synID, "synapse %d is %d->%d, but %d->%d in the source", synID, syn.src, syn.dst, sourceSyn.src, sourceSyn.dst)
+		}
+		for _, end := range []IDType{syn.src, syn.dst} {
+			if _, ok := d.Neurons[end]; !ok {
+				v.fail(UNKNOWN_ENDPOINT, synID, "synapse %d connects neuron %d, which the DNA doesn't have", synID, end)
+			}
+		}
+	}
+	return v.result()
+}
+
+func sortedIndexedIDs(x *IndexedIDs) []IDType {
+	ids := make([]IDType, 0, len(x.IDToIndex))
+	for id := range x.IDToIndex {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+func sortedIDs[V any](m map[IDType]V) []IDType {
+	ids := make([]IDType, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
@@ -0,0 +1,118 @@
+package neuron
+
+import (
+	"errors"
+	"testing"
+)
+
+func validateTestConglomerate() *Conglomerate {
+	c := NewConglomerate()
+	c.AddVisionAndMotor(2, 1)
+	c.AddInterNeuron(0)
+	return c
+}
+
+func TestConglomerateValidate(t *testing.T) {
+	if err := validateTestConglomerate().Validate(); err != nil {
+		t.Errorf("Got error %v", err)
+	}
+
+	for _, test := range []struct {
+		name    string
+		corrupt func(c *Conglomerate)
+		want    Invariant
+	}{
+		{"index", func(c *Conglomerate) { c.NeuronIDs[SENSE].IndexToID[0] = 1 }, INDEX_MISMATCH},
+		{"duplicate", func(c *Conglomerate) { c.NeuronIDs[INTER].InsertID(2) }, DUPLICATE_NEURON},
+		{"srcMap", func(c *Conglomerate) { delete(c.Synapses.srcMap[0], 0) }, SRC_MAP_MISMATCH},
+		{"extra srcMap", func(c *Conglomerate) { c.Synapses.srcMap[1][0] = member }, SRC_MAP_MISMATCH},
+		{"next synapse", func(c *Conglomerate) { c.Synapses.nextID = 1 }, STALE_NEXT_ID},
+		{"next neuron", func(c *Conglomerate) { c.nextNeuronID = 3 }, STALE_NEXT_ID},
+		{"endpoint", func(c *Conglomerate) { c.Synapses.TrackSynapse(10, 0, 9) }, UNKNOWN_ENDPOINT},
+	} {
+		c := validateTestConglomerate()
+		test.corrupt(c)
+		err := c.Validate()
+		errs, ok := err.(ValidationErrors)
+		if !ok || !errs.Has(test.want) {
+			t.Errorf("%s: got %v, want %v", test.name, err, test.want)
+		}
+	}
+}
+
+func TestDNAValidate(t *testing.T) {
+	if err := SimpleTestDNA().Validate(); err != nil {
+		t.Errorf("Got error %v", err)
+	}
+
+	for _, test := range []struct {
+		name    string
+		corrupt func(d *DNA[SignalType])
+		want    Invariant
+	}{
+		{"motor", func(d *DNA[SignalType]) {
+			delete(d.Neurons, 2)
+		}, MISSING_IO},
+		{"endpoint", func(d *DNA[SignalType]) {
+			d.Source.AddInterNeuron(0)
+			d.AddSynapse(2)
+		}, UNKNOWN_ENDPOINT},
+		{"source", func(d *DNA[SignalType]) {
+			d.Synpases.TrackSynapse(7, 0, 2)
+		}, SOURCE_MISMATCH},
+		{"mismatch", func(d *DNA[SignalType]) {
+			d.Synpases.RemoveSynapse(0)
+			d.Synpases.TrackSynapse(0, 1, 2)
+		}, SOURCE_MISMATCH},
+		{"neuron", func(d *DNA[SignalType]) {
+			d.AddNeuron(9, OR)
+		}, UNKNOWN_NEURON},
+		{"op", func(d *DNA[SignalType]) {
+			d.Neurons[2].Op = OperatorType(NumOps())
+		}, INVALID_NEURON},
+		{"source invalid", func(d *DNA[SignalType]) {
+			d.Source.Synapses.nextID = 0
+		}, STALE_NEXT_ID},
+	} {
+		d := SimpleTestDNA()
+		test.corrupt(d)
+		err := d.Validate()
+		errs, ok := err.(ValidationErrors)
+		if !ok || !errs.Has(test.want) {
+			t.Errorf("%s: got %v, want %v", test.name, err, test.want)
+		}
+	}
+}
+
+func TestPlaygroundDebug(t *testing.T) {
+	runner := createTestRunner()
+	runner.play.config.Debug = true
+	runner.play.config.CompactEvery = 2
+	runner.play.InitDNA()
+	// Any broken invariant panics and fails the test.
+	for gen := 0; gen < 6; gen++ {
+		runner.runGeneration(gen)
+	}
+
+	// Crossover keeps MOTOR neurons even without any synapses into them.
+	for id, dna := range runner.play.codes {
+		if err := dna.Validate(); err != nil {
+			t.Errorf("DNA %d: got error %v", id, err)
+		}
+	}
+}
+
+func TestPlaygroundDebugPanics(t *testing.T) {
+	p := CreateTestPlayground()
+	p.config.Debug = true
+	p.source.Synapses.nextID = 0
+
+	defer func() {
+		err, ok := recover().(error)
+		var errs ValidationErrors
+		if !ok || !errors.As(err, &errs) || !errs.Has(STALE_NEXT_ID) {
+			t.Errorf("Got %v, want a panic with %v", err, STALE_NEXT_ID)
+		}
+	}()
+	p.Evolve([]BrainScore{})
+}